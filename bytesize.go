@@ -0,0 +1,155 @@
+package env
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// byteUnits are the accepted byte size suffixes, matched case-insensitively
+var byteUnits = map[string]int64{
+	"":    1,
+	"b":   1,
+	"k":   1000,
+	"kb":  1000,
+	"m":   1000 * 1000,
+	"mb":  1000 * 1000,
+	"g":   1000 * 1000 * 1000,
+	"gb":  1000 * 1000 * 1000,
+	"t":   1000 * 1000 * 1000 * 1000,
+	"tb":  1000 * 1000 * 1000 * 1000,
+	"p":   1000 * 1000 * 1000 * 1000 * 1000,
+	"pb":  1000 * 1000 * 1000 * 1000 * 1000,
+	"ki":  1 << 10,
+	"kib": 1 << 10,
+	"mi":  1 << 20,
+	"mib": 1 << 20,
+	"gi":  1 << 30,
+	"gib": 1 << 30,
+	"ti":  1 << 40,
+	"tib": 1 << 40,
+	"pi":  1 << 50,
+	"pib": 1 << 50,
+}
+
+// byteFormats are the suffixes used for printing, from the largest to the smallest
+var byteFormats = []struct {
+	suffix string
+	size   int64
+}{
+	{"PiB", 1 << 50},
+	{"PB", 1000 * 1000 * 1000 * 1000 * 1000},
+	{"TiB", 1 << 40},
+	{"TB", 1000 * 1000 * 1000 * 1000},
+	{"GiB", 1 << 30},
+	{"GB", 1000 * 1000 * 1000},
+	{"MiB", 1 << 20},
+	{"MB", 1000 * 1000},
+	{"KiB", 1 << 10},
+	{"kB", 1000},
+}
+
+var byteSizePattern = regexp.MustCompile(`^([0-9]+(?:\.[0-9]+)?)\s*([a-zA-Z]*)$`)
+
+// ByteSize is an optional byte count accepting SI (kB, MB, GB) and IEC (KiB, MiB, GiB) suffixes
+type ByteSize int64
+
+// String formats the size using the largest suffix that represents it exactly
+func (v ByteSize) String() string {
+	n := int64(v)
+	if n == 0 {
+		return "0B"
+	}
+
+	best, bestQuotient := "B", n
+	for _, f := range byteFormats {
+		if n%f.size == 0 && n/f.size < bestQuotient {
+			best, bestQuotient = f.suffix, n/f.size
+		}
+	}
+
+	return strconv.FormatInt(bestQuotient, 10) + best
+}
+
+// NonEmptyByteSize is a required ByteSize value
+type NonEmptyByteSize int64
+
+func (v NonEmptyByteSize) String() string { return ByteSize(v).String() }
+
+// asByteSize validates that the input is a byte count with an optional unit suffix
+func asByteSize(s string) (int64, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	m := byteSizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidByteSize, s)
+	}
+
+	unit, ok := byteUnits[strings.ToLower(m[2])]
+	if !ok {
+		return 0, fmt.Errorf("%w: unknown unit %s", ErrInvalidByteSize, m[2])
+	}
+
+	if !strings.Contains(m[1], ".") {
+		n, err := strconv.ParseInt(m[1], 10, 64)
+		if err != nil || n > math.MaxInt64/unit {
+			return 0, fmt.Errorf("%w: %s is too large", ErrInvalidByteSize, s)
+		}
+		return n * unit, nil
+	}
+
+	// Fractions are computed exactly and must come out to a whole number of bytes
+	r, ok := new(big.Rat).SetString(m[1])
+	if !ok {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidByteSize, s)
+	}
+	r.Mul(r, new(big.Rat).SetInt64(unit))
+	if !r.IsInt() {
+		return 0, fmt.Errorf("%w: %s is not a whole number of bytes", ErrInvalidByteSize, s)
+	}
+	if !r.Num().IsInt64() {
+		return 0, fmt.Errorf("%w: %s is too large", ErrInvalidByteSize, s)
+	}
+
+	return r.Num().Int64(), nil
+}
+
+// asNotEmptyByteSize validates that the input is not empty
+func asNotEmptyByteSize(s string) (int64, error) {
+	if _, err := asNotEmpty(s); err != nil {
+		return 0, err
+	}
+	return asByteSize(s)
+}
+
+// byteSizeInBounds validates that a byte count is within the bounds given by the min and max tags
+// The bounds accept the same units as the value
+func byteSizeInBounds(n int64, tag reflect.StructTag) error {
+	if min, ok := tag.Lookup(minTag); ok {
+		m, err := asNotEmptyByteSize(min)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%q", ErrInvalidTag, minTag, min)
+		}
+		if n < m {
+			return fmt.Errorf("%w: %s is less than min %s", ErrOutOfRange, ByteSize(n), min)
+		}
+	}
+
+	if max, ok := tag.Lookup(maxTag); ok {
+		m, err := asNotEmptyByteSize(max)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%q", ErrInvalidTag, maxTag, max)
+		}
+		if n > m {
+			return fmt.Errorf("%w: %s is greater than max %s", ErrOutOfRange, ByteSize(n), max)
+		}
+	}
+
+	return nil
+}
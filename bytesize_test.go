@@ -0,0 +1,101 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestByteSize(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    int64
+		expectedErr error
+	}{
+		{"", 0, nil},
+		{"0", 0, nil},
+		{"1024", 1024, nil},
+		{"512MiB", 512 << 20, nil},
+		{"512 mib", 512 << 20, nil},
+		{"1.5GB", 1500000000, nil},
+		{"1.5GiB", 3 << 29, nil},
+		{"1.0KB", 1000, nil},
+		{"0.5KiB", 512, nil},
+		{"0.5", 0, ErrInvalidByteSize},
+		{"1.0000001KB", 0, ErrInvalidByteSize},
+		{"0.1KiB", 0, ErrInvalidByteSize},
+		{"9999999.5PiB", 0, ErrInvalidByteSize},
+		{"10k", 10000, nil},
+		{"2Ti", 2 << 40, nil},
+		{"-5MB", 0, ErrInvalidByteSize},
+		{"5XB", 0, ErrInvalidByteSize},
+		{"MB", 0, ErrInvalidByteSize},
+		{"9999999PiB", 0, ErrInvalidByteSize},
+	}
+
+	for _, test := range tests {
+		v, err := asByteSize(test.input)
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+			assert.Zero(t, v, test)
+		} else {
+			assert.Nil(t, err, test)
+			assert.Equal(t, test.expected, v, test)
+		}
+	}
+
+	_, err := asNotEmptyByteSize("")
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+}
+
+func TestByteSizeString(t *testing.T) {
+	tests := []struct {
+		input    ByteSize
+		expected string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1000, "1kB"},
+		{1024, "1KiB"},
+		{512 << 20, "512MiB"},
+		{1500000000, "1500MB"},
+		{3 << 29, "1536MiB"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, test.input.String(), test)
+
+		v, err := asByteSize(test.input.String())
+		assert.Nil(t, err, test)
+		assert.Equal(t, int64(test.input), v, test)
+	}
+}
+
+func TestByteSizeBounds(t *testing.T) {
+	type limits struct {
+		Cache  NonEmptyByteSize `env:"CACHE_SIZE" min:"1MiB" max:"1GiB"`
+		Upload ByteSize         `env:"UPLOAD_LIMIT" max:"10MB"`
+	}
+
+	tests := []struct {
+		env         map[string]string
+		expectedErr error
+	}{
+		{map[string]string{"CACHE_SIZE": "512MiB"}, nil},
+		{map[string]string{"CACHE_SIZE": "1GiB", "UPLOAD_LIMIT": "10MB"}, nil},
+		{map[string]string{"CACHE_SIZE": "1.5GiB"}, ErrOutOfRange},
+		{map[string]string{"CACHE_SIZE": "1000kB"}, ErrOutOfRange},
+		{map[string]string{"CACHE_SIZE": "1MiB", "UPLOAD_LIMIT": "10.5MB"}, ErrOutOfRange},
+	}
+
+	for _, test := range tests {
+		e := test.env
+		err := New(&limits{}, &Options{Getenv: func(k string) string { return e[k] }}).Validate()
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+		} else {
+			assert.Nil(t, err, test)
+		}
+	}
+}
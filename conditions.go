@@ -0,0 +1,50 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+const requiredIfTag = "required_if"
+const requiredUnlessTag = "required_unless"
+const excludedWithTag = "excluded_with"
+
+// checkConditions validates a raw value against the conditional requiredness tags of a field
+// Conditions are space separated and refer to env names or field names of the same structure,
+// a condition `NAME=value` holds when NAME has that value and `NAME` holds when NAME is not empty
+func checkConditions(f reflect.StructField, candidate string, lookup func(string) string) error {
+	if conds, ok := f.Tag.Lookup(requiredIfTag); ok && candidate == "" {
+		if cond, ok := anyHolds(conds, lookup); ok {
+			return fmt.Errorf("%w: %s is required if %s", ErrUnexpectedEmptyValue, f.Name, cond)
+		}
+	}
+
+	if conds, ok := f.Tag.Lookup(requiredUnlessTag); ok && candidate == "" {
+		if _, ok := anyHolds(conds, lookup); !ok {
+			return fmt.Errorf("%w: %s is required unless %s", ErrUnexpectedEmptyValue, f.Name, conds)
+		}
+	}
+
+	if conds, ok := f.Tag.Lookup(excludedWithTag); ok && candidate != "" {
+		if cond, ok := anyHolds(conds, lookup); ok {
+			return fmt.Errorf("%w: %s is excluded with %s", ErrExcludedValue, f.Name, cond)
+		}
+	}
+
+	return nil
+}
+
+// anyHolds returns the first condition that holds
+func anyHolds(conds string, lookup func(string) string) (string, bool) {
+	for _, cond := range strings.Fields(conds) {
+		if n := strings.Index(cond, "="); n >= 0 {
+			if lookup(cond[:n]) == cond[n+1:] {
+				return cond, true
+			}
+		} else if lookup(cond) != "" {
+			return cond, true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditions(t *testing.T) {
+	type mailer struct {
+		Mailer       Enum   `env:"MAILER" enum:"smtp,sendmail,ses" default:"sendmail"`
+		SMTPPassword Secret `env:"SMTP_PASSWORD" required_if:"MAILER=smtp"`
+		SESRegion    String `env:"SES_REGION" required_unless:"MAILER=smtp MAILER=sendmail"`
+		TLSCert      String `env:"TLS_CERT" required_if:"TLSKey"`
+		TLSKey       String `env:"TLS_KEY"`
+		Plaintext    Int    `env:"PLAINTEXT" excluded_with:"TLS_CERT"`
+	}
+
+	tests := []struct {
+		env         map[string]string
+		expectedErr error
+	}{
+		{map[string]string{}, nil},
+		{map[string]string{"MAILER": "smtp", "SMTP_PASSWORD": "hunter2"}, nil},
+		{map[string]string{"MAILER": "smtp"}, ErrUnexpectedEmptyValue},
+		{map[string]string{"MAILER": "ses"}, ErrUnexpectedEmptyValue},
+		{map[string]string{"MAILER": "ses", "SES_REGION": "eu-north-1"}, nil},
+		{map[string]string{"TLS_KEY": "key"}, ErrUnexpectedEmptyValue},
+		{map[string]string{"TLS_KEY": "key", "TLS_CERT": "cert"}, nil},
+		{map[string]string{"PLAINTEXT": "1"}, nil},
+		{map[string]string{"PLAINTEXT": "1", "TLS_CERT": "cert"}, ErrExcludedValue},
+	}
+
+	for _, test := range tests {
+		e := test.env
+		err := New(&mailer{}, &Options{Getenv: func(k string) string { return e[k] }}).Validate()
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+		} else {
+			assert.Nil(t, err, test)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package env
+
+import (
+	"fmt"
+)
+
+// Change is a difference in a single environment value between two configurations
+// Secret values are redacted
+type Change struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// Diff compares two populated configurations of the same type field by field
+func Diff(prev, next interface{}, opts ...*Options) ([]Change, error) {
+	pval, err := structValue(prev)
+	if err != nil {
+		return nil, err
+	}
+
+	nval, err := structValue(next)
+	if err != nil {
+		return nil, err
+	}
+
+	if pval.Type() != nval.Type() {
+		return nil, fmt.Errorf("%w: %s and %s", ErrMismatchedTypes, pval.Type(), nval.Type())
+	}
+
+	options := mergeOptions(opts)
+
+	pvars, err := marshalValue(pval, options, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	nvars, err := marshalValue(nval, options, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Variables are matched by name, slices of structures may differ in length
+	nindex := make(map[string]Variable, len(nvars))
+	for _, x := range nvars {
+		nindex[x.Name] = x
+	}
+
+	pindex := make(map[string]bool, len(pvars))
+	changes := []Change{}
+
+	for _, p := range pvars {
+		pindex[p.Name] = true
+		if x := nindex[p.Name]; p.Value != x.Value {
+			changes = append(changes, change(p.Name, p.Value, x.Value, p.Secret || x.Secret))
+		}
+	}
+
+	for _, x := range nvars {
+		if !pindex[x.Name] && x.Value != "" {
+			changes = append(changes, change(x.Name, "", x.Value, x.Secret))
+		}
+	}
+
+	return changes, nil
+}
+
+func change(name, prev, next string, secret bool) Change {
+	if secret {
+		prev, next = Secret(prev).String(), Secret(next).String()
+	}
+	return Change{name, prev, next}
+}
@@ -0,0 +1,81 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	type config struct {
+		Beep NonEmptyString `env:"BEEP"`
+		Boop IntSlice       `env:"BOOP"`
+		Brrt HostPort       `env:"BRRT"`
+		Bzzt Secret         `env:"BZZT"`
+	}
+
+	prev := config{"hello", IntSlice{1, 2}, HostPort{"localhost", "80"}, "hunter2"}
+
+	tests := []struct {
+		name     string
+		next     config
+		expected []Change
+	}{
+		{
+			"identical",
+			prev,
+			[]Change{},
+		},
+		{
+			"changed values",
+			config{"hello", IntSlice{1}, HostPort{}, "hunter2"},
+			[]Change{{"BOOP", "1,2", "1"}, {"BRRT", "localhost:80", ""}},
+		},
+		{
+			"redacted secret",
+			config{"hello", IntSlice{1, 2}, HostPort{"localhost", "80"}, "hunter3"},
+			[]Change{{"BZZT", "[redacted]", "[redacted]"}},
+		},
+		{
+			"removed secret",
+			config{"hello", IntSlice{1, 2}, HostPort{"localhost", "80"}, ""},
+			[]Change{{"BZZT", "[redacted]", ""}},
+		},
+	}
+
+	for _, test := range tests {
+		changes, err := Diff(&prev, &test.next)
+		assert.Nil(t, err, test.name)
+		assert.Equal(t, test.expected, changes, test.name)
+	}
+
+	_, err := Diff(&prev, &struct{}{})
+	assert.True(t, errors.Is(err, ErrMismatchedTypes))
+
+	type pool struct {
+		Upstreams []upstreamEnv `env:"UPSTREAM"`
+	}
+
+	changes, err := Diff(
+		&pool{[]upstreamEnv{{"http://a", 5}, {"http://b", 5}}},
+		&pool{[]upstreamEnv{{"http://a", 10}}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []Change{
+		{"UPSTREAM_0_TIMEOUT", "5", "10"},
+		{"UPSTREAM_1_URL", "http://b", ""},
+		{"UPSTREAM_1_TIMEOUT", "5", ""},
+	}, changes)
+
+	type database struct {
+		DB DSN `env:"DB"`
+	}
+
+	changes, err = Diff(
+		&database{DSN{Driver: "postgres", Host: "db", Database: "app"}},
+		&database{DSN{Driver: "postgres", Host: "db", Database: "app", User: "app", Password: "hunter2"}},
+	)
+	assert.Nil(t, err)
+	assert.Equal(t, []Change{{"DB", "[redacted]", "[redacted]"}}, changes)
+}
@@ -0,0 +1,271 @@
+package env
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"strings"
+)
+
+const requireParamsTag = "require_params"
+
+// DSN is an optional database connection string
+// Postgres and MySQL URLs and libpq style key=value strings are accepted
+type DSN struct {
+	Driver   string
+	Host     string
+	Port     string
+	Database string
+	User     string
+	Password string
+	Params   map[string]string
+}
+
+// URL returns the connection string as a URL including the password
+// A Unix socket directory is passed in the host and port parameters as libpq does
+func (v DSN) URL() *url.URL {
+	if v.Driver == "" {
+		return nil
+	}
+
+	u := &url.URL{Scheme: v.Driver, Path: "/" + v.Database}
+	q := url.Values{}
+	for k, p := range v.Params {
+		q.Set(k, p)
+	}
+
+	switch {
+	case strings.HasPrefix(v.Host, "/"):
+		q.Set("host", v.Host)
+		if v.Port != "" {
+			q.Set("port", v.Port)
+		}
+	case v.Port != "":
+		u.Host = net.JoinHostPort(v.Host, v.Port)
+	case strings.Contains(v.Host, ":"):
+		u.Host = "[" + v.Host + "]"
+	default:
+		u.Host = v.Host
+	}
+
+	if v.Password != "" {
+		u.User = url.UserPassword(v.User, v.Password)
+	} else if v.User != "" {
+		u.User = url.User(v.User)
+	}
+
+	u.RawQuery = q.Encode()
+
+	return u
+}
+
+// String returns the connection string as a URL with the password redacted
+func (v DSN) String() string {
+	u := v.URL()
+	if u == nil {
+		return ""
+	}
+	return u.Redacted()
+}
+
+// unredacted returns the connection string including the password
+func (v DSN) unredacted() string {
+	u := v.URL()
+	if u == nil {
+		return ""
+	}
+	return u.String()
+}
+
+// NonEmptyDSN is a required DSN value
+type NonEmptyDSN DSN
+
+// URL returns the connection string as a URL including the password
+func (v NonEmptyDSN) URL() *url.URL { return DSN(v).URL() }
+
+func (v NonEmptyDSN) String() string { return DSN(v).String() }
+
+func (v NonEmptyDSN) unredacted() string { return DSN(v).unredacted() }
+
+// asDSN validates that the input is a Postgres or MySQL URL or a key=value connection string
+// The password is never included in the errors
+func asDSN(s string) (DSN, error) {
+	if s == "" {
+		return DSN{}, nil
+	}
+
+	var (
+		dsn DSN
+		err error
+	)
+
+	if strings.Contains(s, "://") {
+		dsn, err = parseDSNURL(s)
+	} else {
+		dsn, err = parseDSNKeyValues(s)
+	}
+	if err != nil {
+		return DSN{}, err
+	}
+
+	if dsn.Port != "" {
+		if _, err := asPort(dsn.Port, ""); err != nil {
+			return DSN{}, err
+		}
+	}
+
+	return dsn, nil
+}
+
+// asNotEmptyDSN validates that the input is not empty
+func asNotEmptyDSN(s string) (DSN, error) {
+	if _, err := asNotEmpty(s); err != nil {
+		return DSN{}, err
+	}
+	return asDSN(s)
+}
+
+// checkDSN validates a DSN against the schemes and require_params tags
+// A required parameter is either a name, or name=value for an exact value
+func checkDSN(dsn DSN, tag reflect.StructTag) error {
+	if schemes, ok := tag.Lookup(schemesTag); ok && !contains(strings.Split(schemes, ","), dsn.Driver) {
+		return fmt.Errorf("%w: %s is not one of %s", ErrUnexpectedURLScheme, dsn.Driver, schemes)
+	}
+
+	if params, ok := tag.Lookup(requireParamsTag); ok {
+		for _, param := range strings.Split(params, ",") {
+			name, want := param, ""
+			if n := strings.Index(param, "="); n >= 0 {
+				name, want = param[:n], param[n+1:]
+			}
+
+			got := dsn.Params[name]
+			if got == "" || (want != "" && got != want) {
+				return fmt.Errorf("%w: expected %s", ErrMissingDSNParam, param)
+			}
+		}
+	}
+
+	return nil
+}
+
+func parseDSNURL(s string) (DSN, error) {
+	u, err := url.Parse(s)
+	if err != nil {
+		return DSN{}, fmt.Errorf("%w: unparseable url", ErrInvalidDSNValue)
+	}
+
+	driver := u.Scheme
+	switch driver {
+	case "postgres", "postgresql":
+		driver = "postgres"
+	case "mysql":
+	default:
+		return DSN{}, fmt.Errorf("%w: unknown driver %s", ErrInvalidDSNValue, u.Scheme)
+	}
+
+	dsn := DSN{
+		Driver:   driver,
+		Host:     u.Hostname(),
+		Port:     u.Port(),
+		Database: strings.TrimPrefix(u.Path, "/"),
+		Params:   map[string]string{},
+	}
+
+	if u.User != nil {
+		dsn.User = u.User.Username()
+		dsn.Password, _ = u.User.Password()
+	}
+
+	for k, vs := range u.Query() {
+		dsn.Params[k] = vs[0]
+	}
+
+	// libpq reads a Unix socket directory from the host parameter
+	if dsn.Host == "" && strings.HasPrefix(dsn.Params["host"], "/") {
+		dsn.Host = dsn.Params["host"]
+		delete(dsn.Params, "host")
+
+		if dsn.Port == "" {
+			dsn.Port = dsn.Params["port"]
+			delete(dsn.Params, "port")
+		}
+	}
+
+	return dsn, nil
+}
+
+// parseDSNKeyValues parses a libpq style connection string, values may be single quoted
+func parseDSNKeyValues(s string) (DSN, error) {
+	dsn := DSN{Driver: "postgres", Params: map[string]string{}}
+
+	rs := []rune(s)
+	for n := 0; n < len(rs); {
+		if rs[n] == ' ' || rs[n] == '\t' || rs[n] == '\n' {
+			n++
+			continue
+		}
+
+		start := n
+		for n < len(rs) && rs[n] != '=' && rs[n] != ' ' {
+			n++
+		}
+		key := string(rs[start:n])
+		if key == "" {
+			return DSN{}, fmt.Errorf("%w: missing key", ErrInvalidDSNValue)
+		}
+
+		for n < len(rs) && rs[n] == ' ' {
+			n++
+		}
+		if n >= len(rs) || rs[n] != '=' {
+			return DSN{}, fmt.Errorf("%w: missing value for %s", ErrInvalidDSNValue, key)
+		}
+		n++
+		for n < len(rs) && rs[n] == ' ' {
+			n++
+		}
+
+		var value []rune
+		quoted := n < len(rs) && rs[n] == '\''
+		if quoted {
+			n++
+		}
+		for ; n < len(rs); n++ {
+			r := rs[n]
+			if r == '\\' && n+1 < len(rs) {
+				n++
+				value = append(value, rs[n])
+				continue
+			}
+			if (quoted && r == '\'') || (!quoted && (r == ' ' || r == '\t' || r == '\n')) {
+				break
+			}
+			value = append(value, r)
+		}
+		if quoted {
+			if n >= len(rs) {
+				return DSN{}, fmt.Errorf("%w: unterminated quote for %s", ErrInvalidDSNValue, key)
+			}
+			n++
+		}
+
+		switch key {
+		case "host":
+			dsn.Host = string(value)
+		case "port":
+			dsn.Port = string(value)
+		case "dbname":
+			dsn.Database = string(value)
+		case "user":
+			dsn.User = string(value)
+		case "password":
+			dsn.Password = string(value)
+		default:
+			dsn.Params[key] = string(value)
+		}
+	}
+
+	return dsn, nil
+}
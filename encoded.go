@@ -0,0 +1,136 @@
+package env
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// lenTag requires an exact decoded length in bytes
+const lenTag = "len"
+
+// Base64Bytes is an optional standard or URL-safe base64 value, padded or not, that is redacted when printed
+type Base64Bytes []byte
+
+func (v Base64Bytes) String() string {
+	if len(v) == 0 {
+		return ""
+	}
+	return redacted
+}
+
+// unredacted returns the value in padded standard base64
+func (v Base64Bytes) unredacted() string {
+	return base64.StdEncoding.EncodeToString(v)
+}
+
+// NonEmptyBase64Bytes is a required Base64Bytes value
+type NonEmptyBase64Bytes []byte
+
+func (v NonEmptyBase64Bytes) String() string { return Base64Bytes(v).String() }
+
+func (v NonEmptyBase64Bytes) unredacted() string { return Base64Bytes(v).unredacted() }
+
+// HexBytes is an optional hex encoded value that is redacted when printed
+type HexBytes []byte
+
+func (v HexBytes) String() string {
+	if len(v) == 0 {
+		return ""
+	}
+	return redacted
+}
+
+// unredacted returns the value in lowercase hex
+func (v HexBytes) unredacted() string {
+	return hex.EncodeToString(v)
+}
+
+// NonEmptyHexBytes is a required HexBytes value
+type NonEmptyHexBytes []byte
+
+func (v NonEmptyHexBytes) String() string { return HexBytes(v).String() }
+
+func (v NonEmptyHexBytes) unredacted() string { return HexBytes(v).unredacted() }
+
+// asBase64Bytes decodes standard or URL-safe base64, the padding is optional but must be complete when present
+// Non-canonical values with unused bits set are rejected, the value is never included in the errors
+func asBase64Bytes(s string) ([]byte, error) {
+	if s == "" {
+		return []byte{}, nil
+	}
+
+	enc := base64.StdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.URLEncoding
+	}
+	if !strings.HasSuffix(s, "=") {
+		enc = enc.WithPadding(base64.NoPadding)
+	}
+
+	b, err := enc.Strict().DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed input", ErrInvalidBase64Value)
+	}
+	return b, nil
+}
+
+// asNotEmptyBase64Bytes validates that the input is not empty
+func asNotEmptyBase64Bytes(s string) ([]byte, error) {
+	if _, err := asNotEmpty(s); err != nil {
+		return nil, err
+	}
+	return asBase64Bytes(s)
+}
+
+// asHexBytes decodes a hex string of either case
+// The value is never included in the errors
+func asHexBytes(s string) ([]byte, error) {
+	if s == "" {
+		return []byte{}, nil
+	}
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed input", ErrInvalidHexValue)
+	}
+	return b, nil
+}
+
+// asNotEmptyHexBytes validates that the input is not empty
+func asNotEmptyHexBytes(s string) ([]byte, error) {
+	if _, err := asNotEmpty(s); err != nil {
+		return nil, err
+	}
+	return asHexBytes(s)
+}
+
+// bytesInBounds validates the decoded length against the len, minlen and maxlen tags
+func bytesInBounds(b []byte, f reflect.StructField) error {
+	for _, bound := range []struct {
+		tag  string
+		fail func(n, m int) bool
+		msg  string
+	}{
+		{lenTag, func(n, m int) bool { return n != m }, "is not"},
+		{minLenTag, func(n, m int) bool { return n < m }, "is shorter than"},
+		{maxLenTag, func(n, m int) bool { return n > m }, "is longer than"},
+	} {
+		s, ok := f.Tag.Lookup(bound.tag)
+		if !ok {
+			continue
+		}
+		m, err := strconv.Atoi(s)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%q", ErrInvalidTag, bound.tag, s)
+		}
+		if bound.fail(len(b), m) {
+			return fmt.Errorf("%w: %s %s %d bytes", ErrInvalidLength, f.Name, bound.msg, m)
+		}
+	}
+
+	return nil
+}
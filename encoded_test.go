@@ -0,0 +1,103 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBase64Bytes(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    []byte
+		expectedErr error
+	}{
+		{"", []byte{}, nil},
+		{"aGVsbG8=", []byte("hello"), nil},
+		{"aGVsbG8", []byte("hello"), nil},
+		{"-_8=", []byte{0xfb, 0xff}, nil},
+		{"+/8", []byte{0xfb, 0xff}, nil},
+		{"a", nil, ErrInvalidBase64Value},
+		{"!!!!", nil, ErrInvalidBase64Value},
+		{"YQ==", []byte("a"), nil},
+		{"YQ", []byte("a"), nil},
+		{"YQ=", nil, ErrInvalidBase64Value},
+		{"YQ=====", nil, ErrInvalidBase64Value},
+		{"YR", nil, ErrInvalidBase64Value},
+		{"YR==", nil, ErrInvalidBase64Value},
+	}
+
+	for _, test := range tests {
+		v, err := asBase64Bytes(test.input)
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+		} else {
+			assert.Nil(t, err, test)
+			assert.Equal(t, test.expected, v, test)
+		}
+	}
+
+	_, err := asNotEmptyBase64Bytes("")
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+}
+
+func TestHexBytes(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    []byte
+		expectedErr error
+	}{
+		{"", []byte{}, nil},
+		{"deadBEEF", []byte{0xde, 0xad, 0xbe, 0xef}, nil},
+		{"abc", nil, ErrInvalidHexValue},
+		{"zz", nil, ErrInvalidHexValue},
+	}
+
+	for _, test := range tests {
+		v, err := asHexBytes(test.input)
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+		} else {
+			assert.Nil(t, err, test)
+			assert.Equal(t, test.expected, v, test)
+		}
+	}
+
+	_, err := asNotEmptyHexBytes("")
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+}
+
+func TestEncodedBytesEnv(t *testing.T) {
+	type keyEnv struct {
+		SigningKey NonEmptyBase64Bytes `env:"SIGNING_KEY" len:"4"`
+		Salt       HexBytes            `env:"SALT" minlen:"2"`
+	}
+
+	env := map[string]string{"SIGNING_KEY": "3q2+7w==", "SALT": "0102"}
+	getenv := func(k string) string { return env[k] }
+
+	cf := keyEnv{}
+	assert.Nil(t, New(&cf, &Options{Getenv: getenv}).Validate())
+	assert.Equal(t, NonEmptyBase64Bytes{0xde, 0xad, 0xbe, 0xef}, cf.SigningKey)
+	assert.Equal(t, HexBytes{1, 2}, cf.Salt)
+	assert.Equal(t, redacted, fmt.Sprint(cf.SigningKey))
+	assert.Equal(t, "", HexBytes{}.String())
+
+	vars, err := Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"SIGNING_KEY", "3q2+7w==", true},
+		{"SALT", "0102", true},
+	}, vars)
+
+	env["SIGNING_KEY"] = "3q2+"
+	err = New(&cf, &Options{Getenv: getenv}).Validate()
+	assert.True(t, errors.Is(err, ErrInvalidLength))
+
+	env["SIGNING_KEY"] = "3q2+7w"
+	env["SALT"] = "01"
+	err = New(&cf, &Options{Getenv: getenv}).Validate()
+	assert.True(t, errors.Is(err, ErrInvalidLength))
+}
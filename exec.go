@@ -0,0 +1,116 @@
+package env
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// InheritMode selects how the parent environment is carried over to a child process
+type InheritMode int
+
+// Inheritance modes
+const (
+	// InheritAll keeps the parent environment and overrides it with the configuration
+	InheritAll InheritMode = iota
+	// InheritNone replaces the parent environment with the configuration
+	InheritNone
+	// InheritFiltered keeps the parent values accepted by ExecOptions.Filter, which is required
+	InheritFiltered
+)
+
+// ExecOptions represents the configurable traits of ApplyToCmd
+type ExecOptions struct {
+	Inherit      InheritMode
+	Filter       func(name string) bool
+	AllowSecrets bool
+
+	// Env is used for marshalling the configuration
+	Env *Options
+}
+
+// ApplyToCmd merges a validated configuration into the environment of a command
+// The parent environment is cmd.Env when set and os.Environ otherwise
+func ApplyToCmd(config interface{}, cmd *exec.Cmd, opts ...*ExecOptions) error {
+	options := &ExecOptions{}
+	for _, o := range opts {
+		options = o
+	}
+
+	var envOpts []*Options
+	if options.Env != nil {
+		envOpts = append(envOpts, options.Env)
+	}
+
+	if options.Inherit == InheritFiltered && options.Filter == nil {
+		return ErrMissingFilter
+	}
+
+	vars, err := Marshal(config, envOpts...)
+	if err != nil {
+		return err
+	}
+
+	// Empty secrets carry nothing to leak
+	for _, v := range vars {
+		if v.Secret && v.Value != "" && !options.AllowSecrets {
+			return fmt.Errorf("%w: %s", ErrSecretNotAllowed, v.Name)
+		}
+	}
+
+	parent := cmd.Env
+	if parent == nil {
+		parent = os.Environ()
+	}
+
+	var base []string
+	switch options.Inherit {
+	case InheritAll:
+		base = parent
+	case InheritFiltered:
+		for _, kv := range parent {
+			if options.Filter(envKey(kv)) {
+				base = append(base, kv)
+			}
+		}
+	}
+
+	cmd.Env = mergeEnv(base, vars)
+	return nil
+}
+
+// mergeEnv overrides or appends the variables to a list of key=value pairs
+func mergeEnv(base []string, vars []Variable) []string {
+	out := make([]string, 0, len(base)+len(vars))
+	index := map[string]int{}
+
+	for _, kv := range base {
+		k := envKey(kv)
+		if n, ok := index[k]; ok {
+			out[n] = kv
+			continue
+		}
+		index[k] = len(out)
+		out = append(out, kv)
+	}
+
+	for _, v := range vars {
+		kv := v.Name + "=" + v.Value
+		if n, ok := index[v.Name]; ok {
+			out[n] = kv
+			continue
+		}
+		index[v.Name] = len(out)
+		out = append(out, kv)
+	}
+
+	return out
+}
+
+func envKey(kv string) string {
+	if n := strings.Index(kv, "="); n >= 0 {
+		return kv[:n]
+	}
+	return kv
+}
@@ -0,0 +1,177 @@
+package env
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMarshal(t *testing.T) {
+	type config struct {
+		Beep NonEmptyString `env:"BEEP"`
+		Boop IntSlice       `env:"BOOP"`
+		Brrt HostPort       `env:"BRRT"`
+		Bzzt Secret         `env:"BZZT"`
+	}
+
+	vars, err := Marshal(&config{"hello", IntSlice{1, 2}, HostPort{}, "hunter2"})
+
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"BEEP", "hello", false},
+		{"BOOP", "1,2", false},
+		{"BRRT", "", false},
+		{"BZZT", "hunter2", true},
+	}, vars)
+
+	_, err = Marshal((*config)(nil))
+	assert.True(t, errors.Is(err, ErrUnexpectedNilPointer))
+}
+
+func TestMarshalLists(t *testing.T) {
+	type config struct {
+		Paths    StringSlice   `env:"PATHS" separator:":"`
+		Ports    IntSlice      `env:"PORTS" separator:";"`
+		Peers    HostPortSlice `env:"PEERS"`
+		Networks CIDRSlice     `env:"NETWORKS" separator:" "`
+		Labels   StringMap     `env:"LABELS" separator:";"`
+	}
+
+	env := map[string]string{
+		"PATHS":    "a,b:c",
+		"PORTS":    "1;2",
+		"PEERS":    "a:1,[::1]:2",
+		"NETWORKS": "10.0.0.0/8 fd00::/8",
+		"LABELS":   "y=2;x=1,0",
+	}
+
+	cf := config{}
+	assert.Nil(t, New(&cf, &Options{Getenv: func(k string) string { return env[k] }}).Validate())
+
+	vars, err := Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"PATHS", "a,b:c", false},
+		{"PORTS", "1;2", false},
+		{"PEERS", "a:1,[::1]:2", false},
+		{"NETWORKS", "10.0.0.0/8 fd00::/8", false},
+		{"LABELS", "x=1,0;y=2", false},
+	}, vars)
+
+	marshalled := map[string]string{}
+	for _, x := range vars {
+		marshalled[x.Name] = x.Value
+	}
+
+	roundTrip := config{}
+	assert.Nil(t, New(&roundTrip, &Options{Getenv: func(k string) string { return marshalled[k] }}).Validate())
+	assert.Equal(t, cf, roundTrip)
+}
+
+func TestApplyToCmd(t *testing.T) {
+	type worker struct {
+		Beep NonEmptyString `env:"BEEP"`
+		Boop Int            `env:"BOOP"`
+	}
+
+	type secretWorker struct {
+		Beep NonEmptyString `env:"BEEP"`
+		Bzzt Secret         `env:"BZZT"`
+	}
+
+	type dsnWorker struct {
+		DB DSN `env:"DB"`
+	}
+
+	parent := []string{"PATH=/bin", "BEEP=old", "HOME=/root"}
+
+	tests := []struct {
+		name        string
+		config      interface{}
+		opts        *ExecOptions
+		expected    []string
+		expectedErr error
+	}{
+		{
+			"inherit overrides parent values",
+			&worker{"new", 2},
+			&ExecOptions{},
+			[]string{"PATH=/bin", "BEEP=new", "HOME=/root", "BOOP=2"},
+			nil,
+		},
+		{
+			"replace drops parent values",
+			&worker{"new", 2},
+			&ExecOptions{Inherit: InheritNone},
+			[]string{"BEEP=new", "BOOP=2"},
+			nil,
+		},
+		{
+			"filter keeps accepted parent values",
+			&worker{"new", 2},
+			&ExecOptions{Inherit: InheritFiltered, Filter: func(k string) bool { return k == "PATH" }},
+			[]string{"PATH=/bin", "BEEP=new", "BOOP=2"},
+			nil,
+		},
+		{
+			"secrets are refused",
+			&secretWorker{"new", "hunter2"},
+			&ExecOptions{},
+			parent,
+			ErrSecretNotAllowed,
+		},
+		{
+			"empty secrets are passed",
+			&secretWorker{"new", ""},
+			&ExecOptions{Inherit: InheritNone},
+			[]string{"BEEP=new", "BZZT="},
+			nil,
+		},
+		{
+			"dsns without a password are passed",
+			&dsnWorker{DSN{Driver: "postgres", Host: "db", Database: "app", User: "app"}},
+			&ExecOptions{Inherit: InheritNone},
+			[]string{"DB=postgres://app@db/app"},
+			nil,
+		},
+		{
+			"dsns with a password are refused",
+			&dsnWorker{DSN{Driver: "postgres", Host: "db", Database: "app", User: "app", Password: "hunter2"}},
+			&ExecOptions{},
+			parent,
+			ErrSecretNotAllowed,
+		},
+		{
+			"filter is required",
+			&worker{"new", 2},
+			&ExecOptions{Inherit: InheritFiltered},
+			parent,
+			ErrMissingFilter,
+		},
+		{
+			"secrets are allowed explicitly",
+			&secretWorker{"new", "hunter2"},
+			&ExecOptions{Inherit: InheritNone, AllowSecrets: true},
+			[]string{"BEEP=new", "BZZT=hunter2"},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		cmd := exec.Command("true")
+		cmd.Env = append([]string{}, parent...)
+
+		err := ApplyToCmd(test.config, cmd, test.opts)
+
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test.name)
+			assert.False(t, strings.Contains(strings.Join(cmd.Env, " "), "hunter2"), test.name)
+		} else {
+			assert.Nil(t, err, test.name)
+		}
+		assert.Equal(t, test.expected, cmd.Env, test.name)
+	}
+}
@@ -0,0 +1,63 @@
+package env
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const formatTag = "format"
+
+// valueDecoder is implemented by types decoding their own raw value, such as JSON
+type valueDecoder interface {
+	decodeValue(s string) error
+}
+
+var valueDecoderType = reflect.TypeOf((*valueDecoder)(nil)).Elem()
+
+// isDecoder reports whether a type decodes its own raw value
+func isDecoder(t reflect.Type) bool {
+	return reflect.PtrTo(t).Implements(valueDecoderType)
+}
+
+// hasJSONFormat reports whether a field is tagged with format:"json"
+func hasJSONFormat(f reflect.StructField) bool {
+	return f.Tag.Get(formatTag) == "json"
+}
+
+// decodeJSON unmarshals a raw value into v, an empty value leaves v unchanged
+// Syntax and type errors report the offset of the offending input
+func decodeJSON(s string, v interface{}) error {
+	if s == "" {
+		return nil
+	}
+
+	err := json.Unmarshal([]byte(s), v)
+	if err == nil {
+		return nil
+	}
+
+	var (
+		syntaxErr *json.SyntaxError
+		typeErr   *json.UnmarshalTypeError
+	)
+
+	switch {
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("%w: %v at offset %d", ErrInvalidJSONValue, syntaxErr, syntaxErr.Offset)
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("%w: %v at offset %d", ErrInvalidJSONValue, typeErr, typeErr.Offset)
+	}
+
+	return fmt.Errorf("%w: %v", ErrInvalidJSONValue, err)
+}
+
+// encodeJSON marshals a field value for the environment
+func encodeJSON(v reflect.Value) (string, error) {
+	b, err := json.Marshal(v.Interface())
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
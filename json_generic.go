@@ -0,0 +1,31 @@
+//go:build go1.21
+// +build go1.21
+
+package env
+
+import (
+	"reflect"
+)
+
+// JSON is an optional value of any type decoded from JSON
+type JSON[T any] struct {
+	Value T
+}
+
+func (v *JSON[T]) decodeValue(s string) error {
+	var value T
+	if err := decodeJSON(s, &value); err != nil {
+		return err
+	}
+	v.Value = value
+	return nil
+}
+
+// String returns the value encoded as JSON
+func (v JSON[T]) String() string {
+	s, err := encodeJSON(reflect.ValueOf(v.Value))
+	if err != nil {
+		return ""
+	}
+	return s
+}
@@ -0,0 +1,38 @@
+//go:build go1.21
+// +build go1.21
+
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONType(t *testing.T) {
+	type routing struct {
+		Retry  JSON[retryPolicy]       `env:"RETRY"`
+		Routes JSON[map[string]string] `env:"ROUTES"`
+	}
+
+	env := map[string]string{"RETRY": `{"attempts": 3}`}
+	getenv := func(k string) string { return env[k] }
+
+	cf := routing{}
+	assert.Nil(t, New(&cf, &Options{Getenv: getenv}).Validate())
+	assert.Equal(t, retryPolicy{Attempts: 3}, cf.Retry.Value)
+	assert.Nil(t, cf.Routes.Value)
+
+	vars, err := Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"RETRY", `{"attempts":3,"backoff":null}`, false},
+		{"ROUTES", "null", false},
+	}, vars)
+
+	env["RETRY"] = `[1, 2]`
+	err = New(&cf, &Options{Getenv: getenv}).Validate()
+	assert.True(t, errors.Is(err, ErrInvalidJSONValue))
+	assert.Contains(t, err.Error(), "RETRY")
+}
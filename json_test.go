@@ -0,0 +1,49 @@
+package env
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type retryPolicy struct {
+	Attempts int      `json:"attempts"`
+	Backoff  []string `json:"backoff"`
+}
+
+func TestJSONFormat(t *testing.T) {
+	type routing struct {
+		Routes map[string]string `env:"ROUTES" format:"json"`
+		Retry  retryPolicy       `env:"RETRY" format:"json"`
+		Hosts  []retryPolicy     `env:"POLICIES" format:"json"`
+	}
+
+	env := map[string]string{
+		"ROUTES":   `{"/api": "http://api", "/": "http://web"}`,
+		"RETRY":    `{"attempts": 3, "backoff": ["1s", "5s"]}`,
+		"POLICIES": `[{"attempts": 1}]`,
+	}
+	getenv := func(k string) string { return env[k] }
+
+	cf := routing{}
+	assert.Nil(t, New(&cf, &Options{Getenv: getenv}).Validate())
+	assert.Equal(t, map[string]string{"/api": "http://api", "/": "http://web"}, cf.Routes)
+	assert.Equal(t, retryPolicy{3, []string{"1s", "5s"}}, cf.Retry)
+	assert.Equal(t, []retryPolicy{{1, nil}}, cf.Hosts)
+
+	vars, err := Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, Variable{"RETRY", `{"attempts":3,"backoff":["1s","5s"]}`, false}, vars[1])
+
+	env["RETRY"] = `{"attempts": 3,}`
+	err = New(&cf, &Options{Getenv: getenv}).Validate()
+	assert.True(t, errors.Is(err, ErrInvalidJSONValue))
+	assert.Contains(t, err.Error(), "RETRY")
+	assert.Contains(t, err.Error(), "offset 16")
+
+	env["RETRY"] = `{"attempts": "3"}`
+	err = New(&cf, &Options{Getenv: getenv}).Validate()
+	assert.True(t, errors.Is(err, ErrInvalidJSONValue))
+	assert.Contains(t, err.Error(), "offset")
+}
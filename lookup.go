@@ -0,0 +1,254 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const aliasesTag = "aliases"
+const collectTag = "collect"
+
+// collectSeparator joins collected values for conditions, it cannot occur in an environment value
+const collectSeparator = "\x00"
+
+// reader reads the raw values of a single validation
+type reader struct {
+	opts    *Options
+	sources map[string]string
+	read    map[string]bool
+}
+
+func newReader(opts *Options) *reader {
+	return &reader{opts, map[string]string{}, map[string]bool{}}
+}
+
+// withPrefix returns a reader for a group of variables sharing a prefix
+func (r *reader) withPrefix(prefix string) *reader {
+	opts := *r.opts
+	opts.Prefix = prefix
+	return &reader{&opts, r.sources, r.read}
+}
+
+// environ enumerates the environment, a custom Getenv requires a matching Environ
+// The reason names the feature enumerating the environment
+func (r *reader) environ(reason string) ([]string, error) {
+	if r.opts.Environ == nil {
+		return nil, fmt.Errorf("%w: %s", ErrMissingEnviron, reason)
+	}
+	return r.opts.Environ(), nil
+}
+
+// indexedGroups returns the indices n of the variables named <prefix><n>_<name>
+func (r *reader) indexedGroups(prefix string) (map[int]bool, error) {
+	environ, err := r.environ("indexed groups " + prefix)
+	if err != nil {
+		return nil, err
+	}
+
+	present := map[int]bool{}
+
+	for _, kv := range environ {
+		name := envKey(kv)
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+
+		rest := name[len(prefix):]
+		end := strings.Index(rest, "_")
+		if end <= 0 {
+			continue
+		}
+
+		if n, err := strconv.Atoi(rest[:end]); err == nil && n >= 0 {
+			present[n] = true
+		}
+	}
+
+	return present, nil
+}
+
+// getenv reads a value and records the name as known
+func (r *reader) getenv(name string) string {
+	r.read[name] = true
+	return r.opts.Getenv(name)
+}
+
+// get reads the value of a field by its env name followed by its aliases in order
+// A deprecation warning is emitted when an alias supplies the value
+func (r *reader) get(f reflect.StructField, envName string) (string, error) {
+	names := []string{envName}
+	if aliases, ok := f.Tag.Lookup(aliasesTag); ok {
+		for _, alias := range strings.Split(aliases, ",") {
+			names = append(names, r.opts.Prefix+alias)
+		}
+	}
+
+	var value, source string
+	for _, name := range names {
+		v := r.getenv(name)
+		if v == "" {
+			continue
+		}
+		if source == "" {
+			value, source = v, name
+			continue
+		}
+		if v != value {
+			return "", fmt.Errorf("%w: %s and %s", ErrConflictingValues, source, name)
+		}
+	}
+
+	if source == "" {
+		return "", nil
+	}
+
+	r.sources[envName] = source
+	if source != envName && r.opts.Logf != nil {
+		r.opts.Logf("env: %s is deprecated, use %s instead", source, envName)
+	}
+
+	return value, nil
+}
+
+// collect gathers the NAME_<n> variables in index order with the mode "indexed",
+// or the NAME_<key> variables as key=value pairs in key order with the mode "prefixed"
+// A nil slice is returned if none are set
+func (r *reader) collect(envName, mode string, t reflect.Type) ([]string, error) {
+	if mode != "indexed" && mode != "prefixed" {
+		return nil, fmt.Errorf("%w: %s:%q", ErrInvalidTag, collectTag, mode)
+	}
+	if !isList(t) || (mode == "indexed") != (t.Kind() == reflect.Slice) {
+		return nil, fmt.Errorf("%w: %s:%q cannot be used with %s", ErrInvalidTag, collectTag, mode, t)
+	}
+
+	environ, err := r.environ(collectTag)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix := envName + "_"
+	indices := map[int]string{}
+	var keys []string
+
+	for _, kv := range environ {
+		name := envKey(kv)
+		if !strings.HasPrefix(name, prefix) || len(name) == len(prefix) {
+			continue
+		}
+
+		key := name[len(prefix):]
+		if mode == "prefixed" {
+			keys = append(keys, key)
+			continue
+		}
+
+		n, err := strconv.Atoi(key)
+		if err != nil || n < 0 {
+			continue
+		}
+		if other, ok := indices[n]; ok {
+			return nil, fmt.Errorf("%w: %s and %s", ErrDuplicateKey, other, name)
+		}
+		indices[n] = name
+	}
+
+	var values []string
+
+	if mode == "prefixed" {
+		sort.Strings(keys)
+		for _, key := range keys {
+			values = append(values, key+"="+r.getenv(prefix+key))
+		}
+	} else {
+		ns := make([]int, 0, len(indices))
+		for n := range indices {
+			ns = append(ns, n)
+		}
+		sort.Ints(ns)
+		for _, n := range ns {
+			values = append(values, r.getenv(indices[n]))
+		}
+	}
+
+	return values, nil
+}
+
+// checkUnknown reports the variables with a prefix that were not read
+// Each variable is suggested the closest known name when one is near enough
+func (r *reader) checkUnknown(prefix string) error {
+	environ, err := r.environ("strict prefix " + prefix)
+	if err != nil {
+		return err
+	}
+
+	var unknown []string
+
+	for _, kv := range environ {
+		name := envKey(kv)
+		if !strings.HasPrefix(name, prefix) || r.read[name] {
+			continue
+		}
+		if suggestion := r.suggest(name); suggestion != "" {
+			name = fmt.Sprintf("%s (did you mean %s?)", name, suggestion)
+		}
+		unknown = append(unknown, name)
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+
+	sort.Strings(unknown)
+	return fmt.Errorf("%w: %s", ErrUnknownVariable, strings.Join(unknown, ", "))
+}
+
+// suggest returns the known name closest to name, or the empty string if none is near enough
+func (r *reader) suggest(name string) string {
+	best, bestDistance := "", len(name)/3+1
+
+	for known := range r.read {
+		d := editDistance(name, known)
+		if d < bestDistance || (d == bestDistance && best != "" && known < best) {
+			best, bestDistance = known, d
+		}
+	}
+
+	return best
+}
+
+// editDistance returns the Levenshtein distance of two strings
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
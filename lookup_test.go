@@ -0,0 +1,252 @@
+package env
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAliases(t *testing.T) {
+	type db struct {
+		URL NonEmptyURL `env:"DB_URL" aliases:"DATABASE_URL,PG_URL"`
+	}
+
+	tests := []struct {
+		env             map[string]string
+		expectedURL     NonEmptyURL
+		expectedSource  string
+		expectedWarning bool
+		expectedErr     error
+	}{
+		{map[string]string{"DB_URL": "postgres://new"}, "postgres://new", "DB_URL", false, nil},
+		{map[string]string{"DATABASE_URL": "postgres://old"}, "postgres://old", "DATABASE_URL", true, nil},
+		{map[string]string{"PG_URL": "postgres://older"}, "postgres://older", "PG_URL", true, nil},
+		{map[string]string{"DB_URL": "postgres://new", "DATABASE_URL": "postgres://new"}, "postgres://new", "DB_URL", false, nil},
+		{map[string]string{"DB_URL": "postgres://new", "DATABASE_URL": "postgres://old"}, "", "", false, ErrConflictingValues},
+		{map[string]string{}, "", "", false, ErrUnexpectedEmptyValue},
+	}
+
+	for _, test := range tests {
+		var warnings []string
+		e := test.env
+		cf := db{}
+		a := New(&cf, &Options{
+			Getenv: func(k string) string { return e[k] },
+			Logf:   func(format string, v ...interface{}) { warnings = append(warnings, fmt.Sprintf(format, v...)) },
+		})
+		err := a.Validate()
+
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+			continue
+		}
+
+		assert.Nil(t, err, test)
+		assert.Equal(t, test.expectedURL, cf.URL, test)
+		assert.Equal(t, test.expectedSource, a.Sources()["DB_URL"], test)
+		if test.expectedWarning {
+			assert.Equal(t, []string{"env: " + test.expectedSource + " is deprecated, use DB_URL instead"}, warnings, test)
+		} else {
+			assert.Empty(t, warnings, test)
+		}
+	}
+}
+
+func TestStrictPrefix(t *testing.T) {
+	type app struct {
+		Timeout Int    `env:"MYAPP_TIMEOUT"`
+		Name    String `env:"MYAPP_NAME" aliases:"MYAPP_TITLE"`
+	}
+
+	tests := []struct {
+		env         map[string]string
+		expectedErr string
+	}{
+		{map[string]string{"MYAPP_TIMEOUT": "1", "MYAPP_TITLE": "x", "PATH": "/bin"}, ""},
+		{map[string]string{"MYAPP_TIMOUT": "1"}, "unknown variable: MYAPP_TIMOUT (did you mean MYAPP_TIMEOUT?)"},
+		{map[string]string{"MYAPP_DEBUG": "1", "MYAPP_NAEM": "x"}, "unknown variable: MYAPP_DEBUG, MYAPP_NAEM (did you mean MYAPP_NAME?)"},
+	}
+
+	for _, test := range tests {
+		e := test.env
+		err := New(&app{}, &Options{
+			Getenv: func(k string) string { return e[k] },
+			Environ: func() []string {
+				var kvs []string
+				for k, v := range e {
+					kvs = append(kvs, k+"="+v)
+				}
+				return kvs
+			},
+			Logf:         func(string, ...interface{}) {},
+			StrictPrefix: "MYAPP_",
+		}).Validate()
+
+		if test.expectedErr != "" {
+			assert.True(t, errors.Is(err, ErrUnknownVariable), test)
+			assert.EqualError(t, err, test.expectedErr, test)
+		} else {
+			assert.Nil(t, err, test)
+		}
+	}
+}
+
+func TestStrictConditions(t *testing.T) {
+	type app struct {
+		Cert String `env:"APP_CERT" required_if:"APP_TYPO"`
+	}
+
+	env := map[string]string{"APP_TYPO": "1", "APP_CERT": "cert"}
+	err := New(&app{}, &Options{
+		Getenv:       func(k string) string { return env[k] },
+		Environ:      func() []string { return []string{"APP_TYPO=1", "APP_CERT=cert"} },
+		StrictPrefix: "APP_",
+	}).Validate()
+
+	assert.True(t, errors.Is(err, ErrUnknownVariable))
+	assert.Contains(t, err.Error(), "APP_TYPO")
+}
+
+func TestMissingEnviron(t *testing.T) {
+	type app struct {
+		Timeout Int `env:"MYAPP_TIMEOUT"`
+	}
+
+	type peers struct {
+		Peers StringSlice `env:"MYAPP_PEER" collect:"indexed"`
+	}
+
+	assert.Nil(t, os.Setenv("MYAPP_TIMOUT", "1"))
+	defer os.Unsetenv("MYAPP_TIMOUT")
+
+	getenv := func(string) string { return "" }
+
+	err := New(&app{}, &Options{Getenv: getenv, StrictPrefix: "MYAPP_"}).Validate()
+	assert.True(t, errors.Is(err, ErrMissingEnviron))
+	assert.Contains(t, err.Error(), "strict prefix MYAPP_")
+
+	err = New(&peers{}, &Options{Getenv: getenv}).Validate()
+	assert.True(t, errors.Is(err, ErrMissingEnviron))
+	assert.EqualError(t, err, "MYAPP_PEER: expected an environ with a custom getenv: collect")
+
+	type upstreams struct {
+		Upstreams []upstreamEnv `env:"UPSTREAM"`
+	}
+
+	err = New(&upstreams{}, &Options{Getenv: getenv}).Validate()
+	assert.True(t, errors.Is(err, ErrMissingEnviron))
+	assert.Contains(t, err.Error(), "indexed groups UPSTREAM_")
+
+	err = New(&app{}, &Options{Getenv: getenv, Environ: func() []string { return nil }, StrictPrefix: "MYAPP_"}).Validate()
+	assert.Nil(t, err)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("", ""))
+	assert.Equal(t, 3, editDistance("abc", ""))
+	assert.Equal(t, 1, editDistance("MYAPP_TIMOUT", "MYAPP_TIMEOUT"))
+	assert.Equal(t, 2, editDistance("MYAPP_NAEM", "MYAPP_NAME"))
+}
+
+func TestCollect(t *testing.T) {
+	type peers struct {
+		Peers  NonEmptyHostPortSlice `env:"PEER" collect:"indexed"`
+		Ports  IntSlice              `env:"PORT" collect:"indexed" default:"1,2"`
+		Labels StringMap             `env:"LABEL" collect:"prefixed"`
+		Notes  StringSlice           `env:"NOTE" collect:"indexed"`
+	}
+
+	env := map[string]string{
+		"PEER_10":    "c:3",
+		"PEER_0":     "a:1",
+		"PEER_2":     "b:2",
+		"PEER_URL":   "ignored",
+		"LABEL_team": "x",
+		"LABEL_env":  "y=z",
+		"NOTE_0":     "one, two",
+		"NOTE_1":     "three",
+	}
+	environ := func() []string {
+		var kvs []string
+		for k, v := range env {
+			kvs = append(kvs, k+"="+v)
+		}
+		return kvs
+	}
+
+	cf := peers{}
+	err := New(&cf, &Options{Getenv: func(k string) string { return env[k] }, Environ: environ}).Validate()
+	assert.Nil(t, err)
+	assert.Equal(t, NonEmptyHostPortSlice{{"a", "1"}, {"b", "2"}, {"c", "3"}}, cf.Peers)
+	assert.Equal(t, IntSlice{1, 2}, cf.Ports)
+	assert.Equal(t, StringMap{"team": "x", "env": "y=z"}, cf.Labels)
+	assert.Equal(t, StringSlice{"one, two", "three"}, cf.Notes)
+
+	env["PEER_02"] = "d:4"
+	err = New(&cf, &Options{Getenv: func(k string) string { return env[k] }, Environ: environ}).Validate()
+	assert.True(t, errors.Is(err, ErrDuplicateKey))
+
+	delete(env, "PEER_02")
+	delete(env, "PEER_0")
+	delete(env, "PEER_2")
+	delete(env, "PEER_10")
+	err = New(&cf, &Options{Getenv: func(k string) string { return env[k] }, Environ: environ}).Validate()
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+}
+
+func TestCollectTypes(t *testing.T) {
+	type single struct {
+		Peer String `env:"PEER" collect:"indexed"`
+	}
+
+	type prefixedSlice struct {
+		Peers StringSlice `env:"PEER" collect:"prefixed"`
+	}
+
+	type indexedMap struct {
+		Labels StringMap `env:"LABEL" collect:"indexed"`
+	}
+
+	opts := &Options{
+		Getenv:  func(string) string { return "" },
+		Environ: func() []string { return []string{"PEER_0=a", "LABEL_0=b"} },
+	}
+
+	for _, cf := range []interface{}{&single{}, &prefixedSlice{}, &indexedMap{}} {
+		err := New(cf, opts).Validate()
+		assert.True(t, errors.Is(err, ErrInvalidTag), cf)
+	}
+}
+
+func TestMarshalCollected(t *testing.T) {
+	type cluster struct {
+		Peers  HostPortSlice `env:"PEER" collect:"indexed"`
+		Labels StringMap     `env:"LABEL" collect:"prefixed"`
+	}
+
+	cf := cluster{HostPortSlice{{"a", "1"}, {"b", "2"}}, StringMap{"team": "x", "env": "y=z"}}
+
+	vars, err := Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"PEER_0", "a:1", false},
+		{"PEER_1", "b:2", false},
+		{"LABEL_env", "y=z", false},
+		{"LABEL_team", "x", false},
+	}, vars)
+
+	env := map[string]string{}
+	var kvs []string
+	for _, x := range vars {
+		env[x.Name] = x.Value
+		kvs = append(kvs, x.Name+"="+x.Value)
+	}
+
+	roundTrip := cluster{}
+	opts := &Options{Getenv: func(k string) string { return env[k] }, Environ: func() []string { return kvs }}
+	assert.Nil(t, New(&roundTrip, opts).Validate())
+	assert.Equal(t, cf, roundTrip)
+}
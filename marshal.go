@@ -0,0 +1,253 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Variable is a single environment value marshalled from a configuration
+type Variable struct {
+	Name   string
+	Value  string
+	Secret bool
+}
+
+// Marshal turns a populated environment configuration back into environment variables
+// Secret values are returned unredacted and flagged as such
+func Marshal(config interface{}, opts ...*Options) ([]Variable, error) {
+	rval, err := structValue(config)
+	if err != nil {
+		return nil, err
+	}
+
+	return marshalValue(rval, mergeOptions(opts), nil)
+}
+
+func marshalValue(v reflect.Value, opts *Options, path []string) ([]Variable, error) {
+	t := v.Type()
+	vars := make([]Variable, 0, v.NumField())
+
+	for i := 0; i < v.NumField(); i++ {
+		f := t.Field(i)
+
+		envName, ok := fieldEnvName(f, path, opts)
+		if !ok {
+			if !isNested(f.Type) {
+				return nil, fmt.Errorf("%w: %s", ErrUntaggedField, f.Name)
+			}
+
+			nested, err := marshalValue(v.Field(i), opts, fieldPath(path, f))
+			if err != nil {
+				return nil, err
+			}
+			vars = append(vars, nested...)
+			continue
+		}
+
+		if hasJSONFormat(f) {
+			value, err := encodeJSON(v.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			vars = append(vars, Variable{envName, value, false})
+			continue
+		}
+
+		if isStructSlice(f.Type) {
+			for n := 0; n < v.Field(i).Len(); n++ {
+				elemOpts := *opts
+				elemOpts.Prefix = fmt.Sprintf("%s_%d_", envName, n)
+
+				elem, err := marshalValue(v.Field(i).Index(n), &elemOpts, nil)
+				if err != nil {
+					return nil, err
+				}
+				vars = append(vars, elem...)
+			}
+			continue
+		}
+
+		if _, ok := f.Tag.Lookup(collectTag); ok {
+			collected, err := marshalCollected(v.Field(i), envName)
+			if err != nil {
+				return nil, err
+			}
+			vars = append(vars, collected...)
+			continue
+		}
+
+		value, err := formatValue(v.Field(i), f.Tag)
+		if err != nil {
+			return nil, err
+		}
+
+		vars = append(vars, Variable{envName, value, isSecretValue(v.Field(i))})
+	}
+
+	return vars, nil
+}
+
+// marshalCollected returns a NAME_<n> variable per slice element, or a NAME_<key> variable per map key
+func marshalCollected(v reflect.Value, envName string) ([]Variable, error) {
+	var vars []Variable
+
+	if v.Kind() == reflect.Map {
+		for _, k := range sortedKeys(v) {
+			value, err := formatElem(v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())))
+			if err != nil {
+				return nil, err
+			}
+			vars = append(vars, Variable{envName + "_" + k, value, false})
+		}
+		return vars, nil
+	}
+
+	for n := 0; n < v.Len(); n++ {
+		value, err := formatElem(v.Index(n))
+		if err != nil {
+			return nil, err
+		}
+		vars = append(vars, Variable{fmt.Sprintf("%s_%d", envName, n), value, false})
+	}
+
+	return vars, nil
+}
+
+// structValue dereferences a configuration into its struct value
+func structValue(config interface{}) (reflect.Value, error) {
+	rval := reflect.ValueOf(config)
+
+	if rval.Kind() == reflect.Ptr {
+		if rval.IsNil() {
+			return reflect.Value{}, ErrUnexpectedNilPointer
+		}
+		rval = rval.Elem()
+	}
+
+	if rval.Kind() != reflect.Struct {
+		return reflect.Value{}, ErrExpectedStructValue
+	}
+
+	return rval, nil
+}
+
+// unredacter is implemented by secret values with a redacted String representation
+type unredacter interface {
+	unredacted() string
+}
+
+// formatValue returns the environment representation of a field value
+// Lists are joined by the separator tag of the field so they split back into the same elements
+func formatValue(v reflect.Value, tag reflect.StructTag) (string, error) {
+	if isList(v.Type()) {
+		return formatList(v, tag)
+	}
+
+	if u, ok := v.Interface().(unredacter); ok {
+		return u.unredacted(), nil
+	}
+
+	if isSecret(v.Type()) {
+		return v.String(), nil
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFieldType, v.Type())
+}
+
+// formatList joins the elements of a slice, or the key value pairs of a map in key order
+// Elements are quoted when the field has the quoted tag
+func formatList(v reflect.Value, tag reflect.StructTag) (string, error) {
+	sp, _ := splitterFor(tag, tag.Get("separator"))
+
+	kvSeparator := tag.Get(kvSeparatorTag)
+	if kvSeparator == "" {
+		kvSeparator = "="
+	}
+
+	elems := make([]string, 0, v.Len())
+
+	if v.Kind() == reflect.Map {
+		for _, k := range sortedKeys(v) {
+			value, err := formatElem(v.MapIndex(reflect.ValueOf(k).Convert(v.Type().Key())))
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, sp.quote(k+kvSeparator+value))
+		}
+	} else {
+		for n := 0; n < v.Len(); n++ {
+			value, err := formatElem(v.Index(n))
+			if err != nil {
+				return "", err
+			}
+			elems = append(elems, sp.quote(value))
+		}
+	}
+
+	// A lone empty element is quoted, the empty string reads back as no elements
+	if sp.quoted && len(elems) == 1 && elems[0] == "" {
+		return `""`, nil
+	}
+
+	return strings.Join(elems, sp.separator), nil
+}
+
+// sortedKeys returns the keys of a map with string keys in order
+func sortedKeys(v reflect.Value) []string {
+	keys := make([]string, 0, v.Len())
+	for _, k := range v.MapKeys() {
+		keys = append(keys, k.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatElem returns the representation of a single list element
+func formatElem(v reflect.Value) (string, error) {
+	switch v.Kind() {
+	case reflect.String:
+		return v.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(v.Int(), 10), nil
+	}
+
+	if s, ok := v.Interface().(fmt.Stringer); ok {
+		return s.String(), nil
+	}
+
+	if v.CanAddr() {
+		if s, ok := v.Addr().Interface().(fmt.Stringer); ok {
+			return s.String(), nil
+		}
+	}
+
+	return "", fmt.Errorf("%w: %s", ErrUnknownFieldType, v.Type())
+}
+
+// isSecretValue reports whether a field value is secret, a DSN only is when it has a password
+func isSecretValue(v reflect.Value) bool {
+	switch dsn := v.Interface().(type) {
+	case DSN:
+		return dsn.Password != ""
+	case NonEmptyDSN:
+		return dsn.Password != ""
+	}
+	return isSecret(v.Type())
+}
+
+// isSecret reports whether values of a type must be redacted
+func isSecret(t reflect.Type) bool {
+	switch t.String() {
+	case "env.Secret", "env.NonEmptySecret", "env.DSN", "env.NonEmptyDSN",
+		"env.Base64Bytes", "env.NonEmptyBase64Bytes", "env.HexBytes", "env.NonEmptyHexBytes":
+		return true
+	}
+	return false
+}
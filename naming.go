@@ -0,0 +1,87 @@
+package env
+
+import (
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// NameFunc derives an env name from the path of field names leading to a field
+type NameFunc func(path []string) string
+
+// SnakeCase derives upper snake case env names, e.g. DB.MaxConns becomes DB_MAX_CONNS
+func SnakeCase(path []string) string {
+	parts := make([]string, len(path))
+	for n, name := range path {
+		parts[n] = snakeCase(name)
+	}
+	return strings.Join(parts, "_")
+}
+
+// snakeCase splits a field name at word boundaries, keeping acronyms such as URL intact
+func snakeCase(name string) string {
+	rs := []rune(name)
+	out := make([]rune, 0, len(rs)+4)
+
+	for n, r := range rs {
+		if n > 0 && unicode.IsUpper(r) {
+			prev := rs[n-1]
+			nextIsLower := n+1 < len(rs) && unicode.IsLower(rs[n+1])
+			if unicode.IsLower(prev) || unicode.IsDigit(prev) || (unicode.IsUpper(prev) && nextIsLower) {
+				out = append(out, '_')
+			}
+		}
+		out = append(out, unicode.ToUpper(r))
+	}
+
+	return string(out)
+}
+
+// fieldEnvName returns the prefixed env name of a field from its tag, or derived by the naming strategy
+func fieldEnvName(f reflect.StructField, path []string, opts *Options) (string, bool) {
+	if envName, ok := f.Tag.Lookup(envTag); ok {
+		return opts.Prefix + envName, true
+	}
+
+	if opts.Naming != nil && (!isNested(f.Type) || hasJSONFormat(f)) {
+		return opts.Prefix + opts.Naming(fieldPath(path, f)), true
+	}
+
+	return "", false
+}
+
+// fieldPath returns a copy of the path extended with the field name
+func fieldPath(path []string, f reflect.StructField) []string {
+	return append(append(make([]string, 0, len(path)+1), path...), f.Name)
+}
+
+// isStructSlice reports whether a field is a slice of nested environment structures
+// Named slice types such as CIDRSlice are values
+func isStructSlice(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Name() == "" && isNested(t.Elem())
+}
+
+// isNested reports whether an untagged field is read as a nested environment structure
+// Structures with unexported fields, such as time.Time, cannot be populated and are not nested
+func isNested(t reflect.Type) bool {
+	if t.Kind() != reflect.Struct || isDecoder(t) {
+		return false
+	}
+
+	switch t.String() {
+	case "env.HostPort", "env.NonEmptyHostPort",
+		"env.CIDR", "env.NonEmptyCIDR",
+		"env.DSN", "env.NonEmptyDSN",
+		"env.Time", "env.NonEmptyTime",
+		"env.Location", "env.NonEmptyLocation":
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		if t.Field(i).PkgPath != "" {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,138 @@
+package env
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnakeCase(t *testing.T) {
+	tests := []struct {
+		input    []string
+		expected string
+	}{
+		{[]string{"Beep"}, "BEEP"},
+		{[]string{"MaxConns"}, "MAX_CONNS"},
+		{[]string{"URL"}, "URL"},
+		{[]string{"APIKey"}, "API_KEY"},
+		{[]string{"HTTP2Port"}, "HTTP2_PORT"},
+		{[]string{"DB", "Host"}, "DB_HOST"},
+		{[]string{"Upstream", "TLSCert"}, "UPSTREAM_TLS_CERT"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, SnakeCase(test.input), test)
+	}
+}
+
+func TestNaming(t *testing.T) {
+	type db struct {
+		Host     NonEmptyHostPort
+		MaxConns Int
+		Password Secret `env:"DATABASE_PASSWORD"`
+	}
+
+	type app struct {
+		Name String
+		DB   db
+	}
+
+	env := map[string]string{
+		"NAME":              "beep",
+		"DB_HOST":           "localhost:5432",
+		"DB_MAX_CONNS":      "10",
+		"DATABASE_PASSWORD": "hunter2",
+		"DB_PASSWORD":       "ignored",
+	}
+	opts := &Options{Getenv: func(k string) string { return env[k] }, Naming: SnakeCase}
+
+	cf := app{}
+	assert.Nil(t, New(&cf, opts).Validate())
+	assert.Equal(t, app{"beep", db{NonEmptyHostPort{"localhost", "5432"}, 10, "hunter2"}}, cf)
+
+	vars, err := Marshal(&cf, opts)
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"NAME", "beep", false},
+		{"DB_HOST", "localhost:5432", false},
+		{"DB_MAX_CONNS", "10", false},
+		{"DATABASE_PASSWORD", "hunter2", true},
+	}, vars)
+
+	err = New(&app{}, &Options{Getenv: func(k string) string { return env[k] }}).Validate()
+	assert.True(t, errors.Is(err, ErrUntaggedField))
+}
+
+func TestPrefix(t *testing.T) {
+	type app struct {
+		Beep   NonEmptyString `env:"BEEP" aliases:"BOOP"`
+		Mailer String         `env:"MAILER"`
+		Pass   Secret         `env:"SMTP_PASSWORD" required_if:"MAILER=smtp"`
+		Name   String
+	}
+
+	env := map[string]string{
+		"BEEP":         "unprefixed",
+		"API_BEEP":     "api",
+		"API_NAME":     "api name",
+		"WORKER_BOOP":  "worker",
+		"WORKER_NAME":  "worker name",
+		"API_MAILER":   "smtp",
+		"WORKER_SMTP":  "ignored",
+		"WORKER_MAILS": "ignored",
+	}
+	getenv := func(k string) string { return env[k] }
+	logf := func(string, ...interface{}) {}
+
+	api := app{}
+	err := New(&api, &Options{Getenv: getenv, Logf: logf, Naming: SnakeCase, Prefix: "API_"}).Validate()
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+	assert.True(t, strings.HasPrefix(err.Error(), "API_SMTP_PASSWORD: "), err)
+
+	env["API_SMTP_PASSWORD"] = "hunter2"
+	assert.Nil(t, New(&api, &Options{Getenv: getenv, Logf: logf, Naming: SnakeCase, Prefix: "API_"}).Validate())
+	assert.Equal(t, app{"api", "smtp", "hunter2", "api name"}, api)
+
+	worker := app{}
+	assert.Nil(t, New(&worker, &Options{Getenv: getenv, Logf: logf, Naming: SnakeCase, Prefix: "WORKER_"}).Validate())
+	assert.Equal(t, app{"worker", "", "", "worker name"}, worker)
+
+	vars, err := Marshal(&worker, &Options{Naming: SnakeCase, Prefix: "WORKER_"})
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"WORKER_BEEP", "worker", false},
+		{"WORKER_MAILER", "", false},
+		{"WORKER_SMTP_PASSWORD", "", true},
+		{"WORKER_NAME", "worker name", false},
+	}, vars)
+}
+
+func TestPrefixedErrors(t *testing.T) {
+	type pool struct {
+		Workers Int `env:"WORKERS" min:"1"`
+	}
+
+	type app struct {
+		Pool    pool
+		Timeout Int `env:"TIMEOUT"`
+	}
+
+	tests := []struct {
+		env         map[string]string
+		expectedErr error
+		prefix      string
+	}{
+		{map[string]string{"API_WORKERS": "-5"}, ErrOutOfRange, "API_WORKERS: "},
+		{map[string]string{"API_TIMEOUT": "soon"}, strconv.ErrSyntax, "API_TIMEOUT: "},
+	}
+
+	for _, test := range tests {
+		e := test.env
+		err := New(&app{}, &Options{Getenv: func(k string) string { return e[k] }, Prefix: "API_"}).Validate()
+		assert.True(t, errors.Is(err, test.expectedErr), test)
+		assert.True(t, strings.HasPrefix(err.Error(), test.prefix), err)
+	}
+}
@@ -0,0 +1,101 @@
+package env
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+)
+
+// EnvFile is the path of a file of NAME=value lines, read on every lookup so a Watcher sees changes
+// Blank lines and lines starting with # are ignored, an export prefix is allowed and values may be quoted
+// A missing or unreadable file reads as an empty environment
+type EnvFile string
+
+// Getenv returns the value of a variable in the file, the last line wins when a name is repeated
+func (f EnvFile) Getenv(name string) string {
+	value := ""
+	for _, kv := range f.Environ() {
+		if envKey(kv) == name {
+			value = kv[len(name)+1:]
+		}
+	}
+	return value
+}
+
+// Environ returns the variables in the file as key=value pairs
+func (f EnvFile) Environ() []string {
+	b, err := ioutil.ReadFile(string(f))
+	if err != nil {
+		return nil
+	}
+
+	var kvs []string
+	for _, line := range strings.Split(string(b), "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		n := strings.Index(line, "=")
+		if n <= 0 {
+			continue
+		}
+
+		name, value := strings.TrimSpace(line[:n]), strings.TrimSpace(line[n+1:])
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		kvs = append(kvs, name+"="+value)
+	}
+
+	return kvs
+}
+
+// Options returns options reading the environment from the file
+func (f EnvFile) Options() *Options {
+	return &Options{Getenv: f.Getenv, Environ: f.Environ}
+}
+
+// SecretsDir is a directory holding one file per variable, as mounted by Docker and Kubernetes secrets
+// The file name is the variable name and a single trailing newline is removed from the value
+// Hidden files are skipped, a missing directory reads as an empty environment
+type SecretsDir string
+
+// Getenv returns the contents of the file named after the variable
+func (d SecretsDir) Getenv(name string) string {
+	if name == "" || strings.HasPrefix(name, ".") || strings.ContainsAny(name, `/\`) {
+		return ""
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(string(d), name))
+	if err != nil {
+		return ""
+	}
+
+	value := strings.TrimSuffix(string(b), "\n")
+	return strings.TrimSuffix(value, "\r")
+}
+
+// Environ returns the files in the directory as key=value pairs
+func (d SecretsDir) Environ() []string {
+	entries, err := ioutil.ReadDir(string(d))
+	if err != nil {
+		return nil
+	}
+
+	var kvs []string
+	for _, e := range entries {
+		if e.IsDir() || strings.HasPrefix(e.Name(), ".") {
+			continue
+		}
+		kvs = append(kvs, e.Name()+"="+d.Getenv(e.Name()))
+	}
+
+	return kvs
+}
+
+// Options returns options reading the environment from the directory
+func (d SecretsDir) Options() *Options {
+	return &Options{Getenv: d.Getenv, Environ: d.Environ}
+}
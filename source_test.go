@@ -0,0 +1,99 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnvFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-env")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "app.env")
+	assert.Nil(t, ioutil.WriteFile(name, []byte(`# settings
+BEEP=one
+
+export BOOP = "two words"
+BRRT='x=y'
+BEEP=three
+broken
+`), 0600))
+
+	f := EnvFile(name)
+	assert.Equal(t, "three", f.Getenv("BEEP"))
+	assert.Equal(t, "two words", f.Getenv("BOOP"))
+	assert.Equal(t, "x=y", f.Getenv("BRRT"))
+	assert.Equal(t, "", f.Getenv("broken"))
+	assert.Equal(t, []string{"BEEP=one", "BOOP=two words", "BRRT=x=y", "BEEP=three"}, f.Environ())
+
+	assert.Nil(t, EnvFile(filepath.Join(dir, "missing")).Environ())
+	assert.Equal(t, "", EnvFile(filepath.Join(dir, "missing")).Getenv("BEEP"))
+}
+
+func TestSecretsDir(t *testing.T) {
+	dir, err := ioutil.TempDir("", "go-env")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "DB_PASSWORD"), []byte("hunter2\n"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, "API_KEY"), []byte("abc"), 0600))
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, ".hidden"), []byte("x"), 0600))
+	assert.Nil(t, os.Mkdir(filepath.Join(dir, "nested"), 0700))
+
+	d := SecretsDir(dir)
+	assert.Equal(t, "hunter2", d.Getenv("DB_PASSWORD"))
+	assert.Equal(t, "", d.Getenv(".hidden"))
+	assert.Equal(t, "", d.Getenv("../DB_PASSWORD"))
+
+	environ := d.Environ()
+	sort.Strings(environ)
+	assert.Equal(t, []string{"API_KEY=abc", "DB_PASSWORD=hunter2"}, environ)
+
+	type secrets struct {
+		Password NonEmptySecret `env:"DB_PASSWORD"`
+	}
+
+	cf := secrets{}
+	assert.Nil(t, New(&cf, d.Options(), &Options{StrictPrefix: "DB_"}).Validate())
+	assert.Equal(t, NonEmptySecret("hunter2"), cf.Password)
+}
+
+func TestWatcherEnvFile(t *testing.T) {
+	type config struct {
+		Beep NonEmptyString `env:"BEEP"`
+	}
+
+	dir, err := ioutil.TempDir("", "go-env")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "app.env")
+	assert.Nil(t, ioutil.WriteFile(name, []byte("BEEP=one\n"), 0600))
+
+	w, err := New(&config{}, EnvFile(name).Options()).Watch(&WatchOptions{
+		Files:    []string{name},
+		Interval: 10 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+	defer w.Stop()
+	assert.Equal(t, &config{"one"}, w.Current())
+
+	changed := make(chan interface{}, 1)
+	w.OnChange(func(_, next interface{}) { changed <- next })
+
+	assert.Nil(t, ioutil.WriteFile(name, []byte("BEEP=rotated\n"), 0600))
+
+	select {
+	case next := <-changed:
+		assert.Equal(t, &config{"rotated"}, next)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a reload after the env file changed")
+	}
+}
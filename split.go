@@ -0,0 +1,180 @@
+package env
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+const trimTag = "trim"
+const skipEmptyTag = "skipempty"
+const quotedTag = "quoted"
+
+// splitter splits list values according to the trim, skipempty and quoted tags
+type splitter struct {
+	separator string
+	trim      bool
+	skipEmpty bool
+	quoted    bool
+}
+
+// splitterFor returns the splitter for a field, or false when none of its tags are set
+func splitterFor(tag reflect.StructTag, separator string) (splitter, bool) {
+	if separator == "" {
+		separator = ","
+	}
+
+	sp := splitter{
+		separator: separator,
+		trim:      tagEnabled(tag, trimTag),
+		skipEmpty: tagEnabled(tag, skipEmptyTag),
+		quoted:    tagEnabled(tag, quotedTag),
+	}
+
+	return sp, sp.trim || sp.skipEmpty || sp.quoted
+}
+
+// isList reports whether values of a type are split by a separator
+func isList(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Map:
+		return true
+	case reflect.Slice:
+		return t.Elem().Kind() != reflect.Uint8 && !isStructSlice(t)
+	}
+	return false
+}
+
+// listElements returns the elements of a list value, the empty string has none
+// Collected values are already split, only the trim and skipempty tags apply to them
+func listElements(tag reflect.StructTag, s string, collected []string) ([]string, error) {
+	sp, ok := splitterFor(tag, tag.Get("separator"))
+
+	switch {
+	case collected != nil:
+		return sp.clean(collected), nil
+	case !ok || s == "":
+		return asStringSlice(s, sp.separator)
+	}
+
+	return sp.split(s)
+}
+
+// split splits a value into elements
+// Quoted elements follow RFC 4180, they may contain the separator and a doubled quote stands for a quote
+func (sp splitter) split(s string) ([]string, error) {
+	if !sp.quoted {
+		return sp.clean(strings.Split(s, sp.separator)), nil
+	}
+
+	elems, err := splitQuoted(s, sp.separator, sp.trim)
+	if err != nil {
+		return nil, err
+	}
+
+	// Quoted elements keep their whitespace, splitQuoted trims around them
+	return splitter{skipEmpty: sp.skipEmpty}.clean(elems), nil
+}
+
+// clean trims the elements and drops the empty ones according to the tags
+func (sp splitter) clean(elems []string) []string {
+	if sp.trim {
+		for n := range elems {
+			elems[n] = strings.TrimSpace(elems[n])
+		}
+	}
+
+	if !sp.skipEmpty {
+		return elems
+	}
+
+	out := elems[:0]
+	for _, e := range elems {
+		if e != "" {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// quote returns an element in the form split reads back unchanged
+// Elements containing the separator or a quote, or whitespace that trim would remove, are quoted
+func (sp splitter) quote(s string) string {
+	if !sp.quoted {
+		return s
+	}
+
+	if strings.Contains(s, sp.separator) || strings.Contains(s, `"`) || (sp.trim && strings.TrimSpace(s) != s) {
+		return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+	}
+
+	return s
+}
+
+func splitQuoted(s, separator string, trim bool) ([]string, error) {
+	if utf8.RuneCountInString(separator) != 1 {
+		return nil, fmt.Errorf("%w: quoted values require a single character separator, got %q", ErrInvalidTag, separator)
+	}
+
+	var elems []string
+
+	for n := 0; ; {
+		start := n
+		if trim {
+			for n < len(s) && (s[n] == ' ' || s[n] == '\t') {
+				n++
+			}
+		}
+
+		if n < len(s) && s[n] == '"' {
+			var b strings.Builder
+			closed := false
+			for n++; n < len(s); n++ {
+				if s[n] != '"' {
+					b.WriteByte(s[n])
+					continue
+				}
+				if n+1 < len(s) && s[n+1] == '"' {
+					b.WriteByte('"')
+					n++
+					continue
+				}
+				closed = true
+				n++
+				break
+			}
+			if !closed {
+				return nil, fmt.Errorf("%w: unterminated quote in %q", ErrMalformedQuotes, s[start:])
+			}
+
+			rest := s[n:]
+			end := strings.Index(rest, separator)
+			if end < 0 {
+				end = len(rest)
+			}
+			if trailing := rest[:end]; trailing != "" && (!trim || strings.TrimSpace(trailing) != "") {
+				return nil, fmt.Errorf("%w: unexpected %q after quoted value", ErrMalformedQuotes, trailing)
+			}
+
+			elems = append(elems, b.String())
+			n += end
+		} else {
+			end := strings.Index(s[n:], separator)
+			if end < 0 {
+				end = len(s) - n
+			}
+			elem := s[n : n+end]
+			if trim {
+				elem = strings.TrimSpace(elem)
+			}
+			elems = append(elems, elem)
+			n += end
+		}
+
+		if n >= len(s) {
+			return elems, nil
+		}
+		n += len(separator)
+	}
+}
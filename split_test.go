@@ -0,0 +1,107 @@
+package env
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitter(t *testing.T) {
+	tests := []struct {
+		input       string
+		tag         reflect.StructTag
+		expected    []string
+		expectedErr error
+	}{
+		{"a, b", `trim:"true"`, []string{"a", "b"}, nil},
+		{"a,,b,", `skipempty:"true"`, []string{"a", "b"}, nil},
+		{"a, ,b", `trim:"true" skipempty:"true"`, []string{"a", "b"}, nil},
+		{`"a,b",c`, `quoted:"true"`, []string{"a,b", "c"}, nil},
+		{`"say ""hi""",c`, `quoted:"true"`, []string{`say "hi"`, "c"}, nil},
+		{`"a;b";c`, `quoted:"true" separator:";"`, []string{"a;b", "c"}, nil},
+		{` "a, b" , c `, `quoted:"true" trim:"true"`, []string{"a, b", "c"}, nil},
+		{`"",c`, `quoted:"true" skipempty:"true"`, []string{"c"}, nil},
+		{`a,`, `quoted:"true"`, []string{"a", ""}, nil},
+		{`"a,b`, `quoted:"true"`, nil, ErrMalformedQuotes},
+		{`"a"b,c`, `quoted:"true"`, nil, ErrMalformedQuotes},
+		{`"a" ,c`, `quoted:"true"`, nil, ErrMalformedQuotes},
+		{`a::b`, `quoted:"true" separator:"::"`, nil, ErrInvalidTag},
+	}
+
+	for _, test := range tests {
+		sp, ok := splitterFor(test.tag, test.tag.Get("separator"))
+		assert.True(t, ok, test)
+
+		v, err := sp.split(test.input)
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+		} else {
+			assert.Nil(t, err, test)
+			assert.Equal(t, test.expected, v, test)
+		}
+	}
+
+	_, ok := splitterFor(``, "")
+	assert.False(t, ok)
+}
+
+func TestSplitterTags(t *testing.T) {
+	type lists struct {
+		Names  StringSlice      `env:"NAMES" trim:"true" skipempty:"true"`
+		Ports  NonEmptyIntSlice `env:"PORTS" trim:"true"`
+		Values StringSlice      `env:"VALUES" quoted:"true"`
+		Labels StringMap        `env:"LABELS" trim:"true" quoted:"true"`
+		Name   String           `env:"NAME" trim:"true"`
+	}
+
+	env := map[string]string{
+		"NAMES":  "a, b,, c ",
+		"PORTS":  "80, 443",
+		"VALUES": `"x,y",z`,
+		"LABELS": `team=x, "note=a, b"`,
+		"NAME":   " a, b ",
+	}
+
+	cf := lists{}
+	assert.Nil(t, New(&cf, &Options{Getenv: func(k string) string { return env[k] }}).Validate())
+	assert.Equal(t, StringSlice{"a", "b", "c"}, cf.Names)
+	assert.Equal(t, NonEmptyIntSlice{80, 443}, cf.Ports)
+	assert.Equal(t, StringSlice{"x,y", "z"}, cf.Values)
+	assert.Equal(t, StringMap{"team": "x", "note": "a, b"}, cf.Labels)
+	assert.Equal(t, String(" a, b "), cf.Name)
+
+	cf.Values = append(cf.Values, `say "hi"`)
+	vars, err := Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, Variable{"VALUES", `"x,y",z,"say ""hi"""`, false}, vars[2])
+	assert.Equal(t, Variable{"LABELS", `"note=a, b",team=x`, false}, vars[3])
+
+	marshalled := map[string]string{}
+	for _, x := range vars {
+		marshalled[x.Name] = x.Value
+	}
+
+	roundTrip := lists{}
+	assert.Nil(t, New(&roundTrip, &Options{Getenv: func(k string) string { return marshalled[k] }}).Validate())
+	assert.Equal(t, cf, roundTrip)
+
+	env["VALUES"] = `""`
+	cf = lists{}
+	assert.Nil(t, New(&cf, &Options{Getenv: func(k string) string { return env[k] }}).Validate())
+	assert.Equal(t, StringSlice{""}, cf.Values)
+
+	vars, err = Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, Variable{"VALUES", `""`, false}, vars[2])
+
+	env["VALUES"] = ""
+	cf = lists{}
+	assert.Nil(t, New(&cf, &Options{Getenv: func(k string) string { return env[k] }}).Validate())
+	assert.Equal(t, StringSlice{}, cf.Values)
+
+	env["PORTS"] = " , "
+	err = New(&cf, &Options{Getenv: func(k string) string { return env[k] }}).Validate()
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+}
@@ -0,0 +1,96 @@
+package env
+
+import (
+	"fmt"
+	"time"
+)
+
+const layoutTag = "layout"
+
+// Time is an optional point in time parsed using the layout tag, time.RFC3339 by default
+type Time struct {
+	time.Time
+	layout string
+}
+
+// String formats the time using the layout it was parsed with
+func (v Time) String() string {
+	if v.IsZero() {
+		return ""
+	}
+	if v.layout == "" {
+		return v.Format(time.RFC3339)
+	}
+	return v.Format(v.layout)
+}
+
+// NonEmptyTime is a required Time value
+type NonEmptyTime Time
+
+func (v NonEmptyTime) String() string { return Time(v).String() }
+
+// Location is an optional time zone loaded using time.LoadLocation
+type Location struct {
+	*time.Location
+}
+
+func (v Location) String() string {
+	if v.Location == nil {
+		return ""
+	}
+	return v.Location.String()
+}
+
+// NonEmptyLocation is a required Location value
+type NonEmptyLocation Location
+
+func (v NonEmptyLocation) String() string { return Location(v).String() }
+
+// asTime validates that the input can be parsed using a layout
+// If a layout is not provided time.RFC3339 is used
+func asTime(s, layout string) (Time, error) {
+	if s == "" {
+		return Time{}, nil
+	}
+
+	if layout == "" {
+		layout = time.RFC3339
+	}
+
+	t, err := time.Parse(layout, s)
+	if err != nil {
+		return Time{}, fmt.Errorf("%w: %s does not match layout %s", ErrInvalidTimeValue, s, layout)
+	}
+
+	return Time{t, layout}, nil
+}
+
+// asNotEmptyTime validates that the input is not empty
+func asNotEmptyTime(s, layout string) (Time, error) {
+	if _, err := asNotEmpty(s); err != nil {
+		return Time{}, err
+	}
+	return asTime(s, layout)
+}
+
+// asLocation validates that the input is a time zone known to time.LoadLocation
+func asLocation(s string) (Location, error) {
+	if s == "" {
+		return Location{}, nil
+	}
+
+	loc, err := time.LoadLocation(s)
+	if err != nil {
+		return Location{}, fmt.Errorf("%w: %s", ErrInvalidLocation, s)
+	}
+
+	return Location{loc}, nil
+}
+
+// asNotEmptyLocation validates that the input is not empty
+func asNotEmptyLocation(s string) (Location, error) {
+	if _, err := asNotEmpty(s); err != nil {
+		return Location{}, err
+	}
+	return asLocation(s)
+}
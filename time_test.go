@@ -0,0 +1,82 @@
+package env
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTime(t *testing.T) {
+	tests := []struct {
+		input       string
+		layout      string
+		expected    time.Time
+		expectedErr error
+	}{
+		{"", "", time.Time{}, nil},
+		{"2026-10-18T12:00:00Z", "", time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC), nil},
+		{"2026-10-18", "2006-01-02", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), nil},
+		{"2026-10-18", "", time.Time{}, ErrInvalidTimeValue},
+		{"18.10.2026", "2006-01-02", time.Time{}, ErrInvalidTimeValue},
+	}
+
+	for _, test := range tests {
+		v, err := asTime(test.input, test.layout)
+		if test.expectedErr != nil {
+			assert.True(t, errors.Is(err, test.expectedErr), test)
+			assert.Zero(t, v, test)
+		} else {
+			assert.Nil(t, err, test)
+			assert.True(t, test.expected.Equal(v.Time), test)
+			assert.Equal(t, test.input, v.String(), test)
+		}
+	}
+
+	_, err := asNotEmptyTime("", "")
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+}
+
+func TestLocation(t *testing.T) {
+	v, err := asLocation("Europe/Helsinki")
+	assert.Nil(t, err)
+	assert.Equal(t, "Europe/Helsinki", v.String())
+
+	v, err = asLocation("")
+	assert.Nil(t, err)
+	assert.Equal(t, "", v.String())
+
+	_, err = asLocation("Mars/Olympus_Mons")
+	assert.True(t, errors.Is(err, ErrInvalidLocation))
+
+	_, err = asNotEmptyLocation("")
+	assert.True(t, errors.Is(err, ErrUnexpectedEmptyValue))
+}
+
+func TestTimeTags(t *testing.T) {
+	type launch struct {
+		At       NonEmptyTime     `env:"LAUNCH_AT"`
+		Date     Time             `env:"MAINTENANCE_DATE" layout:"2006-01-02"`
+		TimeZone NonEmptyLocation `env:"TZ" default:"UTC"`
+	}
+
+	env := map[string]string{"LAUNCH_AT": "2026-10-18T12:00:00+03:00", "MAINTENANCE_DATE": "2026-11-01"}
+	cf := launch{}
+	assert.Nil(t, New(&cf, &Options{Getenv: func(k string) string { return env[k] }}).Validate())
+	assert.True(t, cf.At.Equal(time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, time.November, cf.Date.Month())
+	assert.Equal(t, "UTC", cf.TimeZone.String())
+
+	vars, err := Marshal(&cf)
+	assert.Nil(t, err)
+	assert.Equal(t, []Variable{
+		{"LAUNCH_AT", "2026-10-18T12:00:00+03:00", false},
+		{"MAINTENANCE_DATE", "2026-11-01", false},
+		{"TZ", "UTC", false},
+	}, vars)
+
+	env["TZ"] = "Not/AZone"
+	err = New(&cf, &Options{Getenv: func(k string) string { return env[k] }}).Validate()
+	assert.True(t, errors.Is(err, ErrInvalidLocation))
+}
@@ -2,6 +2,9 @@ package env
 
 import (
 	"fmt"
+	"net"
+	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -26,11 +29,26 @@ type URL string
 
 func (v URL) String() string { return string(v) }
 
+// Parsed returns the parsed URL, or nil if the value is empty
+func (v URL) Parsed() *url.URL {
+	if v == "" {
+		return nil
+	}
+	u, err := url.Parse(string(v))
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
 // NonEmptyURL is a required URL value
 type NonEmptyURL string
 
 func (v NonEmptyURL) String() string { return string(v) }
 
+// Parsed returns the parsed URL
+func (v NonEmptyURL) Parsed() *url.URL { return URL(v).Parsed() }
+
 // HostPort is an optional value that is successfully parsed using net.SplitHostPort
 type HostPort struct {
 	Host string
@@ -38,17 +56,64 @@ type HostPort struct {
 }
 
 func (v HostPort) String() string {
+	if v.Host == "" && v.Port == "" {
+		return ""
+	}
 	if strings.Contains(v.Host, ":") {
 		return fmt.Sprintf("[%s]:%s", v.Host, v.Port)
 	}
 	return fmt.Sprintf("%s:%s", v.Host, v.Port)
 }
 
+// PortNumber returns the port as an int, or 0 if it is not numeric
+// Use the tag `port:"numeric"` to ensure the port is a valid port number
+func (v HostPort) PortNumber() int {
+	port, err := strconv.Atoi(v.Port)
+	if err != nil {
+		return 0
+	}
+	return port
+}
+
 // NonEmptyHostPort is a required HostPort value
 type NonEmptyHostPort HostPort
 
 func (v NonEmptyHostPort) String() string { return HostPort(v).String() }
 
+// PortNumber returns the port as an int, or 0 if it is not numeric
+func (v NonEmptyHostPort) PortNumber() int { return HostPort(v).PortNumber() }
+
+// HostPortSlice is a CSV value of HostPort values
+type HostPortSlice []HostPort
+
+func (x HostPortSlice) String() string {
+	out := make([]string, len(x))
+	for n := range x {
+		out[n] = x[n].String()
+	}
+	return strings.Join(out, ",")
+}
+
+// NonEmptyHostPortSlice is a HostPortSlice value with a length > 0 requirement
+type NonEmptyHostPortSlice []HostPort
+
+func (x NonEmptyHostPortSlice) String() string { return HostPortSlice(x).String() }
+
+// Port is an optional port number between 1 and 65535
+type Port int
+
+func (v Port) String() string {
+	if v == 0 {
+		return ""
+	}
+	return strconv.Itoa(int(v))
+}
+
+// NonEmptyPort is a required Port value
+type NonEmptyPort int
+
+func (v NonEmptyPort) String() string { return strconv.Itoa(int(v)) }
+
 // String is an optional string value
 type String string
 
@@ -59,6 +124,24 @@ type NonEmptyString string
 
 func (v NonEmptyString) String() string { return string(v) }
 
+// redacted replaces the value of a secret when printed
+const redacted = "[redacted]"
+
+// Secret is an optional string value that is redacted when printed
+type Secret string
+
+func (v Secret) String() string {
+	if v == "" {
+		return ""
+	}
+	return redacted
+}
+
+// NonEmptySecret is a required Secret value
+type NonEmptySecret string
+
+func (v NonEmptySecret) String() string { return Secret(v).String() }
+
 // Enum is an enumerated set of valid string values
 type Enum string
 
@@ -69,6 +152,16 @@ type NonEmptyEnum string
 
 func (x NonEmptyEnum) String() string { return string(x) }
 
+// EnumSlice is a CSV value of enumerated values
+type EnumSlice []string
+
+func (x EnumSlice) String() string { return strings.Join(x, ",") }
+
+// NonEmptyEnumSlice is a required EnumSlice value
+type NonEmptyEnumSlice []string
+
+func (x NonEmptyEnumSlice) String() string { return strings.Join(x, ",") }
+
 // StringSlice is a CSV value
 type StringSlice []string
 
@@ -87,6 +180,9 @@ func (x IntSlice) String() string {
 	for _, s := range x {
 		out += "," + strconv.Itoa(s)
 	}
+	if out == "" {
+		return out
+	}
 	return out[1:]
 }
 
@@ -98,5 +194,131 @@ func (x NonEmptyIntSlice) String() string {
 	for _, s := range x {
 		out += "," + strconv.Itoa(s)
 	}
+	if out == "" {
+		return out
+	}
 	return out[1:]
 }
+
+// IP is an optional IP address value
+type IP net.IP
+
+func (v IP) String() string {
+	if len(v) == 0 {
+		return ""
+	}
+	return net.IP(v).String()
+}
+
+// NonEmptyIP is a required IP value
+type NonEmptyIP net.IP
+
+func (v NonEmptyIP) String() string { return IP(v).String() }
+
+// IPSlice is a CSV value of IP addresses
+type IPSlice []net.IP
+
+func (x IPSlice) String() string {
+	out := make([]string, len(x))
+	for n, ip := range x {
+		out[n] = ip.String()
+	}
+	return strings.Join(out, ",")
+}
+
+// NonEmptyIPSlice is an IPSlice value with a length > 0 requirement
+type NonEmptyIPSlice []net.IP
+
+func (x NonEmptyIPSlice) String() string { return IPSlice(x).String() }
+
+// CIDR is an optional IP network value in CIDR notation
+type CIDR net.IPNet
+
+func (v CIDR) String() string {
+	if v.IP == nil {
+		return ""
+	}
+	n := net.IPNet(v)
+	return n.String()
+}
+
+// Contains reports whether the network includes ip
+func (v CIDR) Contains(ip net.IP) bool {
+	n := net.IPNet(v)
+	return v.IP != nil && n.Contains(ip)
+}
+
+// NonEmptyCIDR is a required CIDR value
+type NonEmptyCIDR net.IPNet
+
+func (v NonEmptyCIDR) String() string { return CIDR(v).String() }
+
+// Contains reports whether the network includes ip
+func (v NonEmptyCIDR) Contains(ip net.IP) bool { return CIDR(v).Contains(ip) }
+
+// CIDRSlice is a CSV value of IP networks
+type CIDRSlice []net.IPNet
+
+func (x CIDRSlice) String() string {
+	out := make([]string, len(x))
+	for n := range x {
+		out[n] = x[n].String()
+	}
+	return strings.Join(out, ",")
+}
+
+// Contains reports whether any of the networks includes ip
+func (x CIDRSlice) Contains(ip net.IP) bool {
+	for n := range x {
+		if x[n].Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// NonEmptyCIDRSlice is a CIDRSlice value with a length > 0 requirement
+type NonEmptyCIDRSlice []net.IPNet
+
+func (x NonEmptyCIDRSlice) String() string { return CIDRSlice(x).String() }
+
+// Contains reports whether any of the networks includes ip
+func (x NonEmptyCIDRSlice) Contains(ip net.IP) bool { return CIDRSlice(x).Contains(ip) }
+
+// StringMap is a CSV value of key=value pairs
+type StringMap map[string]string
+
+func (x StringMap) String() string {
+	keys := make([]string, 0, len(x))
+	for k := range x {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]string, len(keys))
+	for n, k := range keys {
+		out[n] = k + "=" + x[k]
+	}
+	return strings.Join(out, ",")
+}
+
+// NonEmptyStringMap is a StringMap value with a length > 0 requirement
+type NonEmptyStringMap map[string]string
+
+func (x NonEmptyStringMap) String() string { return StringMap(x).String() }
+
+// IntMap is a CSV value of key=value pairs with int values
+type IntMap map[string]int
+
+func (x IntMap) String() string {
+	m := make(StringMap, len(x))
+	for k, v := range x {
+		m[k] = strconv.Itoa(v)
+	}
+	return m.String()
+}
+
+// NonEmptyIntMap is an IntMap value with a length > 0 requirement
+type NonEmptyIntMap map[string]int
+
+func (x NonEmptyIntMap) String() string { return IntMap(x).String() }
@@ -3,16 +3,34 @@ package env
 import (
 	"errors"
 	"fmt"
+	"log"
 	"net"
 	"net/url"
 	"os"
 	"reflect"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 const envTag = "env"
 const fallbackTag = "default"
+const minTag = "min"
+const maxTag = "max"
+const patternTag = "pattern"
+const minLenTag = "minlen"
+const maxLenTag = "maxlen"
+const foldTag = "fold"
+const ipVersionTag = "ipversion"
+const portTag = "port"
+const serviceTag = "service"
+const defaultPortTag = "defaultport"
+const kvSeparatorTag = "kvseparator"
+const schemesTag = "schemes"
+const requirePathTag = "require_path"
+const noUserinfoTag = "no_userinfo"
 
 // Known error outcomes
 var (
@@ -26,48 +44,133 @@ var (
 	ErrUnknownFieldType        = errors.New("unknown field type")
 	ErrPartialURLValue         = errors.New("expected url to have Scheme and Host")
 	ErrInvalidEnumValue        = errors.New("invalid enum value")
+	ErrSecretNotAllowed        = errors.New("secret value not allowed")
+	ErrMismatchedTypes         = errors.New("expected values of the same type")
+	ErrOutOfRange              = errors.New("value out of range")
+	ErrInvalidTag              = errors.New("invalid tag value")
+	ErrPatternMismatch         = errors.New("value does not match pattern")
+	ErrInvalidLength           = errors.New("invalid value length")
+	ErrExcludedValue           = errors.New("unexpected value")
+	ErrConflictingValues       = errors.New("conflicting values")
+	ErrUnknownVariable         = errors.New("unknown variable")
+	ErrInvalidIPValue          = errors.New("invalid ip address")
+	ErrInvalidCIDRValue        = errors.New("invalid cidr")
+	ErrUnexpectedIPVersion     = errors.New("unexpected ip version")
+	ErrInvalidPortValue        = errors.New("invalid port")
+	ErrUnexpectedURLScheme     = errors.New("unexpected url scheme")
+	ErrMissingURLPath          = errors.New("expected url to have a path")
+	ErrUnexpectedURLUserinfo   = errors.New("unexpected url userinfo")
+	ErrInvalidDSNValue         = errors.New("invalid dsn")
+	ErrMissingDSNParam         = errors.New("missing dsn parameter")
+	ErrInvalidByteSize         = errors.New("invalid byte size")
+	ErrInvalidTimeValue        = errors.New("invalid time")
+	ErrInvalidLocation         = errors.New("invalid time zone")
+	ErrMalformedPair           = errors.New("malformed key value pair")
+	ErrDuplicateKey            = errors.New("duplicate key")
+	ErrMalformedQuotes         = errors.New("malformed quotes")
+	ErrInvalidJSONValue        = errors.New("invalid json")
+	ErrInvalidBase64Value      = errors.New("invalid base64")
+	ErrInvalidHexValue         = errors.New("invalid hex")
+	ErrMissingEnviron          = errors.New("expected an environ with a custom getenv")
+	ErrMissingFilter           = errors.New("expected a filter to inherit filtered")
 )
 
 // Options represents the library's configurable traits
 type Options struct {
-	Getenv Getter
+	Getenv  Getter
+	Environ Environ
+	Logf    Logger
+
+	// Prefix is prepended to every env name, e.g. BEEP is read from API_BEEP with the prefix API_
+	Prefix string
+
+	// Naming derives the env names of untagged fields, explicit tags always win
+	Naming NameFunc
+
+	// StrictPrefix rejects variables with the prefix that are not read by any field
+	StrictPrefix string
 }
 
 // AssertedEnvironment represents an environment configuration and a value getter
 type AssertedEnvironment struct {
-	config interface{}
-	opts   *Options
+	config  interface{}
+	opts    *Options
+	sources map[string]string
+}
+
+// Validator is implemented by environment structures with rules spanning several fields
+// Validate is called after all fields of the structure have been populated
+type Validator interface {
+	Validate() error
 }
 
 // Getter is used to retrieve values for populating an environment structure
 type Getter func(string) string
 
-var defaultConfig = Options{os.Getenv}
+// Environ is used to enumerate the environment as key=value pairs
+type Environ func() []string
+
+// Logger is used to emit warnings, such as the use of a deprecated env name
+type Logger func(format string, v ...interface{})
+
+var defaultConfig = Options{Getenv: os.Getenv, Environ: os.Environ, Logf: log.Printf}
 
 // New constructs a new AssertedEnvironment using a provided value getter
 func New(config interface{}, opts ...*Options) *AssertedEnvironment {
-	options := &Options{defaultConfig.Getenv}
+	return &AssertedEnvironment{config: config, opts: mergeOptions(opts)}
+}
+
+// mergeOptions applies the provided options over the defaults
+// Environ only defaults to os.Environ with the default Getenv, so names and values come from the same source
+func mergeOptions(opts []*Options) *Options {
+	options := &Options{Getenv: defaultConfig.Getenv, Logf: defaultConfig.Logf}
+	customGetenv := false
 
 	for _, o := range opts {
-		options.Getenv = o.Getenv
+		if o.Getenv != nil {
+			options.Getenv = o.Getenv
+			customGetenv = true
+		}
+		if o.Environ != nil {
+			options.Environ = o.Environ
+		}
+		if o.Logf != nil {
+			options.Logf = o.Logf
+		}
+		options.Naming = o.Naming
+		options.Prefix = o.Prefix
+		options.StrictPrefix = o.StrictPrefix
+	}
+
+	if options.Environ == nil && !customGetenv {
+		options.Environ = defaultConfig.Environ
 	}
 
-	return &AssertedEnvironment{config, options}
+	return options
 }
 
 // Validate reads and validates the environment values
 func (e *AssertedEnvironment) Validate() error {
-	return validate(e.config, e.opts.Getenv)
+	r := newReader(e.opts)
+	err := validate(e.config, r)
+	e.sources = r.sources
+	return err
 }
 
 // MustValidate validates the environment and panics on any validation error
 func (e *AssertedEnvironment) MustValidate() {
-	if err := validate(e.config, e.opts.Getenv); err != nil {
+	if err := e.Validate(); err != nil {
 		panic(err)
 	}
 }
 
-func validate(a interface{}, getenv Getter) error {
+// Sources returns the env names that supplied the values of the most recent validation
+// keyed by the env tag name, a name differs from its key when it is an alias
+func (e *AssertedEnvironment) Sources() map[string]string {
+	return e.sources
+}
+
+func validate(a interface{}, r *reader) error {
 	reflectType := reflect.TypeOf(a)
 
 	if reflectType.Kind() != reflect.Ptr {
@@ -80,16 +183,22 @@ func validate(a interface{}, getenv Getter) error {
 
 	rval := reflect.ValueOf(a)
 
-	finalValue, err := getValue(reflectType.Elem(), getenv)
+	finalValue, err := getValue(reflectType.Elem(), r, nil)
 	if err != nil {
 		return err
 	}
 
+	if r.opts.StrictPrefix != "" {
+		if err := r.checkUnknown(r.opts.StrictPrefix); err != nil {
+			return err
+		}
+	}
+
 	rval.Elem().Set(finalValue)
 	return nil
 }
 
-func getValue(t reflect.Type, getenv Getter) (reflect.Value, error) {
+func getValue(t reflect.Type, r *reader, path []string) (reflect.Value, error) {
 	k := t.Kind()
 
 	if k != reflect.Struct {
@@ -98,134 +207,586 @@ func getValue(t reflect.Type, getenv Getter) (reflect.Value, error) {
 
 	v := reflect.New(t).Elem()
 
-	for i := 0; i < v.NumField(); i++ {
+	// All raw values are read before decoding, conditional tags refer to them
+	candidates := make([]string, t.NumField())
+	collected := make([][]string, t.NumField())
+	raw := map[string]string{}
+
+	for i := 0; i < t.NumField(); i++ {
 		f := t.Field(i)
 
-		if !v.Field(i).CanSet() {
-			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnsettableField, f.Name)
+		envName, ok := fieldEnvName(f, path, r.opts)
+		if !ok || (isStructSlice(f.Type) && !hasJSONFormat(f)) {
+			continue
 		}
 
 		var (
-			ok                           bool
-			candidate, envName, fallback string
-			typ                          reflect.Type
+			candidate string
+			err       error
 		)
 
-		if envName, ok = f.Tag.Lookup(envTag); !ok {
-			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUntaggedField, f.Name)
+		if mode, ok := f.Tag.Lookup(collectTag); ok {
+			collected[i], err = r.collect(envName, mode, f.Type)
+			candidate = strings.Join(collected[i], collectSeparator)
+		} else {
+			candidate, err = r.get(f, envName)
+		}
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", envName, err)
 		}
 
-		candidate = getenv(envName)
-
-		if candidate == "" {
-			if fallback, ok = f.Tag.Lookup(fallbackTag); ok {
+		if candidate == "" && collected[i] == nil {
+			if fallback, ok := f.Tag.Lookup(fallbackTag); ok {
 				candidate = fallback
 			}
 		}
 
-		typ = v.Field(i).Type()
+		candidates[i] = candidate
+		raw[envName] = candidate
+		raw[f.Name] = candidate
+	}
 
-		switch typ.String() {
-		case "env.Int":
-			valid, err := asInt(candidate)
+	// Conditions refer to field names or unprefixed env names
+	// Variables only named by conditions are not consumed, strict mode still reports them
+	lookup := func(name string) string {
+		if candidate, ok := raw[name]; ok {
+			return candidate
+		}
+		if candidate, ok := raw[r.opts.Prefix+name]; ok {
+			return candidate
+		}
+		return r.opts.Getenv(r.opts.Prefix + name)
+	}
+
+	for i := 0; i < v.NumField(); i++ {
+		f := t.Field(i)
+
+		if !v.Field(i).CanSet() {
+			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnsettableField, f.Name)
+		}
+
+		envName, ok := fieldEnvName(f, path, r.opts)
+		if !ok {
+			if !isNested(f.Type) {
+				return reflect.Value{}, fmt.Errorf("%w: %s", ErrUntaggedField, f.Name)
+			}
+
+			// Untagged struct fields are nested environment structures
+			nested, err := getValue(f.Type, r, fieldPath(path, f))
 			if err != nil {
 				return reflect.Value{}, err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+			v.Field(i).Set(nested)
+			continue
+		}
 
-		case "env.NonEmptyInt":
-			valid, err := asNotEmptyInt(candidate)
+		if isStructSlice(f.Type) && !hasJSONFormat(f) {
+			valid, err := getStructSlice(f.Type, r, envName, f.Tag)
 			if err != nil {
 				return reflect.Value{}, err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+			v.Field(i).Set(valid)
+			continue
+		}
 
-		case "env.String":
-			v.Field(i).Set(reflect.ValueOf(candidate).Convert(typ))
+		// Errors name the variable, including its prefix
+		if err := decodeField(v.Field(i), t, i, candidates[i], collected[i], lookup); err != nil {
+			return reflect.Value{}, fmt.Errorf("%s: %w", envName, err)
+		}
+	}
 
-		case "env.NonEmptyString":
-			valid, err := asNotEmpty(candidate)
-			if err != nil {
-				return reflect.Value{}, err
+	if validator, ok := v.Addr().Interface().(Validator); ok {
+		if err := validator.Validate(); err != nil {
+			return reflect.Value{}, err
+		}
+	}
+
+	return v, nil
+}
+
+// decodeField populates a tagged field from its raw value
+func decodeField(fv reflect.Value, owner reflect.Type, i int, candidate string, collected []string, lookup func(string) string) error {
+	f := owner.Field(i)
+
+	if err := checkConditions(f, candidate, lookup); err != nil {
+		return err
+	}
+
+	typ := fv.Type()
+
+	if hasJSONFormat(f) {
+		return decodeJSON(candidate, fv.Addr().Interface())
+	}
+
+	if isDecoder(typ) {
+		return fv.Addr().Interface().(valueDecoder).decodeValue(candidate)
+	}
+
+	kvSeparator := f.Tag.Get(kvSeparatorTag)
+	if collected != nil {
+		kvSeparator = "="
+	}
+
+	// Lists are split into elements once, the decoders never see the separator
+	var elems []string
+	if isList(typ) {
+		var err error
+		if elems, err = listElements(f.Tag, candidate, collected); err != nil {
+			return err
+		}
+	}
+
+	switch typ.String() {
+	case "env.Int":
+		valid, err := asInt(candidate)
+		if err != nil {
+			return err
+		}
+		if candidate != "" {
+			if err := inBounds(valid, f.Tag); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.URL":
-			valid, err := asURL(candidate)
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.NonEmptyInt":
+		valid, err := asNotEmptyInt(candidate)
+		if err != nil {
+			return err
+		}
+		if err := inBounds(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.String", "env.Secret":
+		if candidate != "" {
+			if err := matchesConstraints(candidate, owner, i); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(candidate).Convert(typ))
 
-		case "env.NonEmptyURL":
-			valid, err := asNotEmptyURL(candidate)
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.NonEmptyString", "env.NonEmptySecret":
+		valid, err := asNotEmpty(candidate)
+		if err != nil {
+			return err
+		}
+		if err := matchesConstraints(valid, owner, i); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.URL":
+		valid, err := asURL(candidate)
+		if err != nil {
+			return err
+		}
+		if candidate != "" {
+			if err := checkURL(valid, f.Tag); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.Enum":
-			valid, err := asEnum(candidate, f.Tag.Get("enum"))
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.NonEmptyURL":
+		valid, err := asNotEmptyURL(candidate)
+		if err != nil {
+			return err
+		}
+		if err := checkURL(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.Enum":
+		valid, err := asEnum(candidate, f.Tag.Get("enum"), tagEnabled(f.Tag, foldTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyEnum":
+		valid, err := asNotEmptyEnum(candidate, f.Tag.Get("enum"), tagEnabled(f.Tag, foldTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.EnumSlice":
+		valid, err := asEnumSlice(elems, f.Tag.Get("enum"), tagEnabled(f.Tag, foldTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyEnumSlice":
+		valid, err := asNotEmptyEnumSlice(elems, f.Tag.Get("enum"), tagEnabled(f.Tag, foldTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.StringSlice":
+		valid := elems
+		if err := allMatchConstraints(valid, owner, i); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyStringSlice":
+		valid, err := asNotEmptyStringSlice(elems)
+		if err != nil {
+			return err
+		}
+		if err := allMatchConstraints(valid, owner, i); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.IntSlice":
+		valid, err := asIntSlice(elems)
+		if err != nil {
+			return err
+		}
+		if err := allInBounds(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyIntSlice":
+		valid, err := asNotEmptyIntSlice(elems)
+		if err != nil {
+			return err
+		}
+		if err := allInBounds(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.StringMap":
+		valid, err := asStringMap(elems, kvSeparator)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyStringMap":
+		valid, err := asNotEmptyStringMap(elems, kvSeparator)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.IntMap":
+		valid, err := asIntMap(elems, kvSeparator)
+		if err != nil {
+			return err
+		}
+		if err := mapInBounds(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyIntMap":
+		valid, err := asNotEmptyIntMap(elems, kvSeparator)
+		if err != nil {
+			return err
+		}
+		if err := mapInBounds(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.HostPort":
+		valid, err := asHostPort(candidate)
+		if err != nil {
+			return err
+		}
+		if candidate != "" && f.Tag.Get(portTag) == "numeric" {
+			if valid, err = withNumericPort(valid, f.Tag.Get(serviceTag)); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.NonEmptyEnum":
-			valid, err := asNotEmptyEnum(candidate, f.Tag.Get("enum"))
+	case "env.NonEmptyHostPort":
+		valid, err := asNotEmptyHostPort(candidate)
+		if err != nil {
+			return err
+		}
+		if f.Tag.Get(portTag) == "numeric" {
+			hp, err := withNumericPort(HostPort(valid), f.Tag.Get(serviceTag))
 			if err != nil {
-				return reflect.Value{}, err
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+			valid = NonEmptyHostPort(hp)
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.StringSlice":
-			valid, err := asStringSlice(candidate, f.Tag.Get("separator"))
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.HostPortSlice":
+		valid, err := asHostPortSlice(elems, f.Tag.Get(defaultPortTag))
+		if err != nil {
+			return err
+		}
+		if f.Tag.Get(portTag) == "numeric" {
+			if err := withNumericPorts(valid, f.Tag.Get(serviceTag)); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.NonEmptyStringSlice":
-			valid, err := asNotEmptyStringSlice(candidate, f.Tag.Get("separator"))
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.NonEmptyHostPortSlice":
+		valid, err := asNotEmptyHostPortSlice(elems, f.Tag.Get(defaultPortTag))
+		if err != nil {
+			return err
+		}
+		if f.Tag.Get(portTag) == "numeric" {
+			if err := withNumericPorts(valid, f.Tag.Get(serviceTag)); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.IntSlice":
-			valid, err := asIntSlice(candidate, f.Tag.Get("separator"))
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.DSN":
+		valid, err := asDSN(candidate)
+		if err != nil {
+			return err
+		}
+		if candidate != "" {
+			if err := checkDSN(valid, f.Tag); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.NonEmptyIntSlice":
-			valid, err := asNotEmptyIntSlice(candidate, f.Tag.Get("separator"))
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.NonEmptyDSN":
+		valid, err := asNotEmptyDSN(candidate)
+		if err != nil {
+			return err
+		}
+		if err := checkDSN(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.ByteSize":
+		valid, err := asByteSize(candidate)
+		if err != nil {
+			return err
+		}
+		if candidate != "" {
+			if err := byteSizeInBounds(valid, f.Tag); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.HostPort":
-			valid, err := asHostPort(candidate)
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.NonEmptyByteSize":
+		valid, err := asNotEmptyByteSize(candidate)
+		if err != nil {
+			return err
+		}
+		if err := byteSizeInBounds(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.Base64Bytes":
+		valid, err := asBase64Bytes(candidate)
+		if err != nil {
+			return err
+		}
+		if candidate != "" {
+			if err := bytesInBounds(valid, f); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		case "env.NonEmptyHostPort":
-			valid, err := asNotEmptyHostPort(candidate)
-			if err != nil {
-				return reflect.Value{}, err
+	case "env.NonEmptyBase64Bytes":
+		valid, err := asNotEmptyBase64Bytes(candidate)
+		if err != nil {
+			return err
+		}
+		if err := bytesInBounds(valid, f); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.HexBytes":
+		valid, err := asHexBytes(candidate)
+		if err != nil {
+			return err
+		}
+		if candidate != "" {
+			if err := bytesInBounds(valid, f); err != nil {
+				return err
 			}
-			v.Field(i).Set(reflect.ValueOf(valid).Convert(typ))
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
 
-		default:
-			return reflect.Value{}, fmt.Errorf("%w: %s", ErrUnknownFieldType, typ)
+	case "env.NonEmptyHexBytes":
+		valid, err := asNotEmptyHexBytes(candidate)
+		if err != nil {
+			return err
 		}
+		if err := bytesInBounds(valid, f); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.Time":
+		valid, err := asTime(candidate, f.Tag.Get(layoutTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyTime":
+		valid, err := asNotEmptyTime(candidate, f.Tag.Get(layoutTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.Location":
+		valid, err := asLocation(candidate)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyLocation":
+		valid, err := asNotEmptyLocation(candidate)
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.Port":
+		valid, err := asPort(candidate, f.Tag.Get(serviceTag))
+		if err != nil {
+			return err
+		}
+		if candidate != "" {
+			if err := inBounds(valid, f.Tag); err != nil {
+				return err
+			}
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyPort":
+		valid, err := asNotEmptyPort(candidate, f.Tag.Get(serviceTag))
+		if err != nil {
+			return err
+		}
+		if err := inBounds(valid, f.Tag); err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.IP":
+		valid, err := asIP(candidate, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyIP":
+		valid, err := asNotEmptyIP(candidate, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.IPSlice":
+		valid, err := asIPSlice(elems, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyIPSlice":
+		valid, err := asNotEmptyIPSlice(elems, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.CIDR":
+		valid, err := asCIDR(candidate, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyCIDR":
+		valid, err := asNotEmptyCIDR(candidate, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.CIDRSlice":
+		valid, err := asCIDRSlice(elems, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	case "env.NonEmptyCIDRSlice":
+		valid, err := asNotEmptyCIDRSlice(elems, f.Tag.Get(ipVersionTag))
+		if err != nil {
+			return err
+		}
+		fv.Set(reflect.ValueOf(valid).Convert(typ))
+
+	default:
+		return fmt.Errorf("%w: %s", ErrUnknownFieldType, typ)
 	}
 
-	return v, nil
+	return nil
+}
+
+// getStructSlice populates a slice of structures from the NAME_<n>_ prefixed variable groups
+// Reading stops at the first index without any variables, the min and max tags bound the length
+func getStructSlice(t reflect.Type, r *reader, envName string, tag reflect.StructTag) (reflect.Value, error) {
+	present, err := r.indexedGroups(envName + "_")
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	s := reflect.MakeSlice(t, 0, len(present))
+	for n := 0; present[n]; n++ {
+		elem, err := getValue(t.Elem(), r.withPrefix(fmt.Sprintf("%s_%d_", envName, n)), nil)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		s = reflect.Append(s, elem)
+	}
+
+	if min, ok := tag.Lookup(minTag); ok {
+		m, err := strconv.Atoi(min)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: %s:%q", ErrInvalidTag, minTag, min)
+		}
+		if s.Len() < m {
+			return reflect.Value{}, fmt.Errorf("%w: %s has %d elements, expected at least %d", ErrOutOfRange, envName, s.Len(), m)
+		}
+	}
+
+	if max, ok := tag.Lookup(maxTag); ok {
+		m, err := strconv.Atoi(max)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("%w: %s:%q", ErrInvalidTag, maxTag, max)
+		}
+		if s.Len() > m {
+			return reflect.Value{}, fmt.Errorf("%w: %s has %d elements, expected at most %d", ErrOutOfRange, envName, s.Len(), m)
+		}
+	}
+
+	return s, nil
 }
 
 // asNotEmpty validates input is not the empty string
@@ -260,6 +821,125 @@ func asNotEmptyInt(s string) (int, error) {
 	return asInt(s)
 }
 
+// inBounds validates that a number is within the bounds given by the min and max tags
+func inBounds(n int, tag reflect.StructTag) error {
+	if min, ok := tag.Lookup(minTag); ok {
+		m, err := strconv.Atoi(min)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%q", ErrInvalidTag, minTag, min)
+		}
+		if n < m {
+			return fmt.Errorf("%w: %d is less than min %d", ErrOutOfRange, n, m)
+		}
+	}
+
+	if max, ok := tag.Lookup(maxTag); ok {
+		m, err := strconv.Atoi(max)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%q", ErrInvalidTag, maxTag, max)
+		}
+		if n > m {
+			return fmt.Errorf("%w: %d is greater than max %d", ErrOutOfRange, n, m)
+		}
+	}
+
+	return nil
+}
+
+// allInBounds validates that every number is within the bounds given by the min and max tags
+func allInBounds(ns []int, tag reflect.StructTag) error {
+	for _, n := range ns {
+		if err := inBounds(n, tag); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// mapInBounds validates that every value of a map is within the bounds given by the min and max tags
+func mapInBounds(m map[string]int, tag reflect.StructTag) error {
+	for k, n := range m {
+		if err := inBounds(n, tag); err != nil {
+			return fmt.Errorf("%w: %s", err, k)
+		}
+	}
+	return nil
+}
+
+// patternKey identifies the pattern tag of a struct field
+type patternKey struct {
+	owner reflect.Type
+	field int
+}
+
+// patterns caches the compiled pattern tags by struct field
+var patterns sync.Map
+
+// compiledPattern compiles the pattern tag of a struct field once
+func compiledPattern(owner reflect.Type, i int, pattern string) (*regexp.Regexp, error) {
+	key := patternKey{owner, i}
+	if re, ok := patterns.Load(key); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s:%q: %v", ErrInvalidTag, patternTag, pattern, err)
+	}
+
+	patterns.Store(key, re)
+	return re, nil
+}
+
+// matchesConstraints validates a string against the pattern, minlen and maxlen tags of a struct field
+// Secret values are never included in the error
+func matchesConstraints(s string, owner reflect.Type, i int) error {
+	f := owner.Field(i)
+	n := utf8.RuneCountInString(s)
+
+	if min, ok := f.Tag.Lookup(minLenTag); ok {
+		m, err := strconv.Atoi(min)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%q", ErrInvalidTag, minLenTag, min)
+		}
+		if n < m {
+			return fmt.Errorf("%w: %s is shorter than minlen %d", ErrInvalidLength, f.Name, m)
+		}
+	}
+
+	if max, ok := f.Tag.Lookup(maxLenTag); ok {
+		m, err := strconv.Atoi(max)
+		if err != nil {
+			return fmt.Errorf("%w: %s:%q", ErrInvalidTag, maxLenTag, max)
+		}
+		if n > m {
+			return fmt.Errorf("%w: %s is longer than maxlen %d", ErrInvalidLength, f.Name, m)
+		}
+	}
+
+	if pattern, ok := f.Tag.Lookup(patternTag); ok {
+		re, err := compiledPattern(owner, i, pattern)
+		if err != nil {
+			return err
+		}
+		if !re.MatchString(s) {
+			return fmt.Errorf("%w: %s does not match %s", ErrPatternMismatch, f.Name, pattern)
+		}
+	}
+
+	return nil
+}
+
+// allMatchConstraints validates every string against the pattern, minlen and maxlen tags of a struct field
+func allMatchConstraints(ss []string, owner reflect.Type, i int) error {
+	for _, s := range ss {
+		if err := matchesConstraints(s, owner, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // asURL validates that the input can be parsed as a URL
 func asURL(s string) (string, error) {
 	if s == "" {
@@ -275,6 +955,35 @@ func asURL(s string) (string, error) {
 	return u.String(), nil
 }
 
+// checkURL validates a parsed URL against the schemes, require_path and no_userinfo tags
+// The URL is not included in the userinfo error as it may contain a password
+func checkURL(s string, tag reflect.StructTag) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+
+	if schemes, ok := tag.Lookup(schemesTag); ok && !contains(strings.Split(schemes, ","), u.Scheme) {
+		return fmt.Errorf("%w: %s is not one of %s", ErrUnexpectedURLScheme, u.Scheme, schemes)
+	}
+
+	if tagEnabled(tag, requirePathTag) && strings.Trim(u.Path, "/") == "" {
+		return fmt.Errorf("%w: %s", ErrMissingURLPath, u.Redacted())
+	}
+
+	if tagEnabled(tag, noUserinfoTag) && u.User != nil {
+		return fmt.Errorf("%w: %s://%s", ErrUnexpectedURLUserinfo, u.Scheme, u.Host)
+	}
+
+	return nil
+}
+
+// tagEnabled reports whether a boolean tag is present and not "false"
+func tagEnabled(tag reflect.StructTag, key string) bool {
+	v, ok := tag.Lookup(key)
+	return ok && v != "false"
+}
+
 // asNotEmptyURL validates that the input is not empty and can be parsed as a URL
 func asNotEmptyURL(s string) (string, error) {
 	_, err := asNotEmpty(s)
@@ -284,25 +993,64 @@ func asNotEmptyURL(s string) (string, error) {
 	return asURL(s)
 }
 
+// enums caches the split values of enum tags
+var enums sync.Map
+
+// enumValues splits an enum tag once
+func enumValues(vals string) []string {
+	if vs, ok := enums.Load(vals); ok {
+		return vs.([]string)
+	}
+
+	vs := strings.Split(vals, ",")
+	enums.Store(vals, vs)
+	return vs
+}
+
 // asEnum validates that the input exists in a defined set of values
-func asEnum(s string, vals string) (string, error) {
+// With fold the input is matched case-insensitively and the canonical spelling is returned
+func asEnum(s, vals string, fold bool) (string, error) {
 	if s == "" {
 		return "", nil
 	}
-	vs := strings.Split(vals, ",")
-	if !contains(vs, s) {
-		return "", fmt.Errorf("%w: %s", ErrInvalidEnumValue, s)
+
+	for _, v := range enumValues(vals) {
+		if v == s || (fold && strings.EqualFold(v, s)) {
+			return v, nil
+		}
 	}
-	return s, nil
+
+	return "", fmt.Errorf("%w: %s is not one of %s", ErrInvalidEnumValue, s, strings.Join(enumValues(vals), ", "))
 }
 
 // asNotEmptyEnum validates that the input is not empty
-func asNotEmptyEnum(s, vals string) (string, error) {
+func asNotEmptyEnum(s, vals string, fold bool) (string, error) {
 	_, err := asNotEmpty(s)
 	if err != nil {
 		return "", err
 	}
-	return asEnum(s, vals)
+	return asEnum(s, vals, fold)
+}
+
+// asEnumSlice validates every element against the set of values
+func asEnumSlice(ss []string, vals string, fold bool) ([]string, error) {
+	out := make([]string, len(ss))
+	for n, x := range ss {
+		var err error
+		if out[n], err = asNotEmptyEnum(x, vals, fold); err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// asNotEmptyEnumSlice validates that the input has at least one element
+func asNotEmptyEnumSlice(ss []string, vals string, fold bool) ([]string, error) {
+	if err := asNotEmptyList(ss); err != nil {
+		return nil, err
+	}
+	return asEnumSlice(ss, vals, fold)
 }
 
 // asStringSlice splits a string value by a separator
@@ -319,54 +1067,209 @@ func asStringSlice(s, separator string) ([]string, error) {
 	return strings.Split(s, splitBy), nil
 }
 
-// asNotEmptyStringSlice validates that the input is not empty
+// asNotEmptyStringSlice validates that the input has at least one element
 // It does not validate non-zero length values
-func asNotEmptyStringSlice(s, separator string) ([]string, error) {
-	if _, err := asNotEmpty(s); err != nil {
+func asNotEmptyStringSlice(ss []string) ([]string, error) {
+	if err := asNotEmptyList(ss); err != nil {
 		return nil, err
 	}
+	return ss, nil
+}
 
-	v, err := asStringSlice(s, separator)
-	if err != nil {
-		return nil, err
-	}
-	if len(v) == 0 {
-		return nil, ErrExpectedAtLeastOneValue
+// asNotEmptyList validates that a list value has at least one element
+func asNotEmptyList(ss []string) error {
+	if len(ss) == 0 {
+		return ErrUnexpectedEmptyValue
 	}
-	return v, nil
+	return nil
 }
 
 type intParser func(string) (int, error)
 
-// asIntSlice splits a string value by a separator and validates that the values can be parsed as an int
-// If a separator is not provided the comma is used
-func asIntSlice(s, separator string) ([]int, error) {
-	stringVals, err := asStringSlice(s, separator)
-	if err != nil {
+// asIntSlice validates that the elements can be parsed as an int
+func asIntSlice(ss []string) ([]int, error) {
+	var err error
+	intVals := make([]int, len(ss))
+	for n := range ss {
+		if intVals[n], err = asNotEmptyInt(ss[n]); err != nil {
+			return nil, err
+		}
+	}
+	return intVals, nil
+}
+
+// asNotEmptyIntSlice validates that the input has at least one element
+func asNotEmptyIntSlice(ss []string) ([]int, error) {
+	if err := asNotEmptyList(ss); err != nil {
+		return nil, err
+	}
+	return asIntSlice(ss)
+}
+
+// asIP validates that the input can be parsed as an IP address
+// The ipversion tag value 4 or 6 restricts the address to IPv4 or IPv6
+func asIP(s, version string) (net.IP, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidIPValue, s)
+	}
+
+	if err := hasIPVersion(ip, version); err != nil {
+		return nil, err
+	}
+
+	return ip, nil
+}
+
+// asNotEmptyIP validates that the input is not empty
+func asNotEmptyIP(s, version string) (net.IP, error) {
+	if _, err := asNotEmpty(s); err != nil {
 		return nil, err
 	}
-	intVals := make([]int, len(stringVals))
-	for n := range stringVals {
-		if intVals[n], err = asNotEmptyInt(stringVals[n]); err != nil {
+	return asIP(s, version)
+}
+
+// asIPSlice validates that the elements can be parsed as IP addresses
+func asIPSlice(ss []string, version string) ([]net.IP, error) {
+	var err error
+	ips := make([]net.IP, len(ss))
+	for n := range ss {
+		if ips[n], err = asNotEmptyIP(ss[n], version); err != nil {
 			return nil, err
 		}
 	}
-	return intVals, nil
+	return ips, nil
 }
 
-// asNotEmptyIntSlice validates that the input is not empty
-func asNotEmptyIntSlice(s, separator string) ([]int, error) {
+// asNotEmptyIPSlice validates that the input has at least one element
+func asNotEmptyIPSlice(ss []string, version string) ([]net.IP, error) {
+	if err := asNotEmptyList(ss); err != nil {
+		return nil, err
+	}
+	return asIPSlice(ss, version)
+}
+
+// asCIDR validates that the input can be parsed as an IP network in CIDR notation
+// The ipversion tag value 4 or 6 restricts the network to IPv4 or IPv6
+func asCIDR(s, version string) (net.IPNet, error) {
+	if s == "" {
+		return net.IPNet{}, nil
+	}
+
+	_, ipNet, err := net.ParseCIDR(s)
+	if err != nil {
+		return net.IPNet{}, fmt.Errorf("%w: %s", ErrInvalidCIDRValue, s)
+	}
+
+	if err := hasIPVersion(ipNet.IP, version); err != nil {
+		return net.IPNet{}, err
+	}
+
+	return *ipNet, nil
+}
+
+// asNotEmptyCIDR validates that the input is not empty
+func asNotEmptyCIDR(s, version string) (net.IPNet, error) {
 	if _, err := asNotEmpty(s); err != nil {
+		return net.IPNet{}, err
+	}
+	return asCIDR(s, version)
+}
+
+// asCIDRSlice validates that the elements can be parsed as IP networks
+func asCIDRSlice(ss []string, version string) ([]net.IPNet, error) {
+	var err error
+	nets := make([]net.IPNet, len(ss))
+	for n := range ss {
+		if nets[n], err = asNotEmptyCIDR(ss[n], version); err != nil {
+			return nil, err
+		}
+	}
+	return nets, nil
+}
+
+// asNotEmptyCIDRSlice validates that the input has at least one element
+func asNotEmptyCIDRSlice(ss []string, version string) ([]net.IPNet, error) {
+	if err := asNotEmptyList(ss); err != nil {
+		return nil, err
+	}
+	return asCIDRSlice(ss, version)
+}
+
+// hasIPVersion validates that an address matches the ipversion tag value
+func hasIPVersion(ip net.IP, version string) error {
+	switch version {
+	case "":
+		return nil
+	case "4":
+		if ip.To4() == nil {
+			return fmt.Errorf("%w: expected IPv4 address %s", ErrUnexpectedIPVersion, ip)
+		}
+	case "6":
+		if ip.To4() != nil {
+			return fmt.Errorf("%w: expected IPv6 address %s", ErrUnexpectedIPVersion, ip)
+		}
+	default:
+		return fmt.Errorf("%w: %s:%q", ErrInvalidTag, ipVersionTag, version)
+	}
+	return nil
+}
+
+// asStringMap splits the elements into key value pairs by a key value separator
+// If a key value separator is not provided the equals sign is used
+func asStringMap(pairs []string, kvSeparator string) (map[string]string, error) {
+	splitBy := kvSeparator
+	if splitBy == "" {
+		splitBy = "="
+	}
+
+	m := make(map[string]string, len(pairs))
+	for _, pair := range pairs {
+		kv := strings.SplitN(pair, splitBy, 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("%w: %q", ErrMalformedPair, pair)
+		}
+		if _, ok := m[kv[0]]; ok {
+			return nil, fmt.Errorf("%w: %q", ErrDuplicateKey, kv[0])
+		}
+		m[kv[0]] = kv[1]
+	}
+	return m, nil
+}
+
+// asNotEmptyStringMap validates that the input has at least one element
+func asNotEmptyStringMap(pairs []string, kvSeparator string) (map[string]string, error) {
+	if err := asNotEmptyList(pairs); err != nil {
 		return nil, err
 	}
+	return asStringMap(pairs, kvSeparator)
+}
 
-	v, err := asIntSlice(s, separator)
+// asIntMap splits the elements into key value pairs and validates that the values can be parsed as an int
+func asIntMap(pairs []string, kvSeparator string) (map[string]int, error) {
+	stringVals, err := asStringMap(pairs, kvSeparator)
 	if err != nil {
 		return nil, err
-	} else if len(v) == 0 {
-		return nil, ErrExpectedAtLeastOneValue
 	}
-	return v, nil
+	intVals := make(map[string]int, len(stringVals))
+	for k, v := range stringVals {
+		if intVals[k], err = asNotEmptyInt(v); err != nil {
+			return nil, fmt.Errorf("%w: %s", err, k)
+		}
+	}
+	return intVals, nil
+}
+
+// asNotEmptyIntMap validates that the input has at least one element
+func asNotEmptyIntMap(pairs []string, kvSeparator string) (map[string]int, error) {
+	if err := asNotEmptyList(pairs); err != nil {
+		return nil, err
+	}
+	return asIntMap(pairs, kvSeparator)
 }
 
 // asHostPort validates that a value is successfully parsed by net.SplitHostPort
@@ -383,6 +1286,99 @@ func asHostPort(s string) (HostPort, error) {
 	return HostPort{host, port}, nil
 }
 
+// asPort validates that the input is a port number between 1 and 65535
+// If a network is provided, service names such as "https" are resolved using net.LookupPort
+func asPort(s, network string) (int, error) {
+	if s == "" {
+		return 0, nil
+	}
+
+	port, err := strconv.Atoi(s)
+	if err != nil {
+		if network == "" {
+			return 0, fmt.Errorf("%w: %s", ErrInvalidPortValue, s)
+		}
+		if port, err = net.LookupPort(network, s); err != nil {
+			return 0, fmt.Errorf("%w: %s: %v", ErrInvalidPortValue, s, err)
+		}
+	}
+
+	if port < 1 || port > 65535 {
+		return 0, fmt.Errorf("%w: port %d is not between 1 and 65535", ErrOutOfRange, port)
+	}
+
+	return port, nil
+}
+
+// asNotEmptyPort validates that the input is not empty
+func asNotEmptyPort(s, network string) (int, error) {
+	if _, err := asNotEmpty(s); err != nil {
+		return 0, err
+	}
+	return asPort(s, network)
+}
+
+// withNumericPort validates that the port of a HostPort is a port number and normalises it to digits
+func withNumericPort(hp HostPort, network string) (HostPort, error) {
+	port, err := asNotEmptyPort(hp.Port, network)
+	if err != nil {
+		return HostPort{}, err
+	}
+	return HostPort{hp.Host, strconv.Itoa(port)}, nil
+}
+
+// withNumericPorts validates that the port of every HostPort is a port number and normalises them to digits
+func withNumericPorts(hps []HostPort, network string) error {
+	for n := range hps {
+		hp, err := withNumericPort(hps[n], network)
+		if err != nil {
+			return err
+		}
+		hps[n] = hp
+	}
+	return nil
+}
+
+// asHostPortSlice validates that the elements are parsed by net.SplitHostPort
+// If a default port is provided it is used for the values without a port
+func asHostPortSlice(ss []string, defaultPort string) ([]HostPort, error) {
+	hps := make([]HostPort, len(ss))
+	for n := range ss {
+		hp, err := asNotEmptyHostPort(withDefaultPort(ss[n], defaultPort))
+		if err != nil {
+			return nil, err
+		}
+		hps[n] = HostPort(hp)
+	}
+	return hps, nil
+}
+
+// asNotEmptyHostPortSlice validates that the input has at least one element
+func asNotEmptyHostPortSlice(ss []string, defaultPort string) ([]HostPort, error) {
+	if err := asNotEmptyList(ss); err != nil {
+		return nil, err
+	}
+	return asHostPortSlice(ss, defaultPort)
+}
+
+// withDefaultPort appends a port to an address without one
+// Both bare and bracketed IPv6 addresses are recognised
+func withDefaultPort(s, port string) string {
+	if port == "" || s == "" {
+		return s
+	}
+
+	if strings.HasPrefix(s, "[") && strings.HasSuffix(s, "]") {
+		return net.JoinHostPort(s[1:len(s)-1], port)
+	}
+
+	if !strings.Contains(s, ":") || net.ParseIP(s) != nil {
+		return net.JoinHostPort(s, port)
+	}
+
+	return s
+}
+
 // asNotEmptyHostPort validates the the input is not empty
 func asNotEmptyHostPort(s string) (NonEmptyHostPort, error) {
 	if _, err := asNotEmpty(s); err != nil {
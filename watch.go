@@ -0,0 +1,210 @@
+package env
+
+import (
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// WatchOptions represents the configurable traits of a Watcher
+type WatchOptions struct {
+	// Signals trigger a reload, SIGHUP is used when none are provided
+	Signals []os.Signal
+	// Files are polled for changes, a change triggers a reload
+	// The process environment cannot change, so reloading only reads new values from a file source
+	// such as EnvFile or SecretsDir, which should be listed here
+	Files []string
+	// Interval is the file polling interval, one second is used when not provided
+	Interval time.Duration
+}
+
+// Watcher re-validates an environment configuration and publishes it when valid
+type Watcher struct {
+	env     *AssertedEnvironment
+	current atomic.Value
+
+	mu        sync.Mutex
+	onChange  []func(prev, next interface{})
+	onError   []func(error)
+	pending   []published
+	notifying bool
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// published is a configuration change waiting for its OnChange callbacks
+type published struct {
+	prev, next interface{}
+}
+
+// Watch validates the environment and keeps re-validating it on signals and file changes
+// The initial validation must succeed for the Watcher to be constructed
+func (e *AssertedEnvironment) Watch(opts ...*WatchOptions) (*Watcher, error) {
+	options := &WatchOptions{Signals: []os.Signal{syscall.SIGHUP}, Interval: time.Second}
+
+	for _, o := range opts {
+		if len(o.Signals) > 0 {
+			options.Signals = o.Signals
+		}
+		if o.Interval > 0 {
+			options.Interval = o.Interval
+		}
+		options.Files = o.Files
+	}
+
+	w := &Watcher{env: e, done: make(chan struct{})}
+	stamps := statFiles(options.Files)
+	if err := w.Reload(); err != nil {
+		return nil, err
+	}
+
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, options.Signals...)
+
+	go w.run(sigs, options.Files, stamps, options.Interval)
+
+	return w, nil
+}
+
+// Current returns a pointer to the most recent valid configuration
+// The returned value is shared by all readers and must not be modified
+func (w *Watcher) Current() interface{} {
+	return w.current.Load()
+}
+
+// OnChange registers a callback invoked with the previous and the new configuration
+func (w *Watcher) OnChange(f func(prev, next interface{})) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onChange = append(w.onChange, f)
+}
+
+// OnError registers a callback invoked when a triggered reload fails validation
+func (w *Watcher) OnError(f func(error)) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.onError = append(w.onError, f)
+}
+
+// Reload validates the environment and publishes the result if it is valid
+// Changes are delivered to the OnChange callbacks in the order they were published. The callbacks are
+// called without holding the lock, so they may use the Watcher; a change published while callbacks
+// are running is delivered by the caller already delivering, after the current change
+func (w *Watcher) Reload() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := w.publish(); err != nil {
+		return err
+	}
+
+	if w.notifying {
+		return nil
+	}
+
+	w.notifying = true
+	defer func() { w.notifying = false }()
+
+	for len(w.pending) > 0 {
+		change := w.pending[0]
+		w.pending = w.pending[1:]
+		w.notify(change, append([]func(prev, next interface{}){}, w.onChange...))
+	}
+
+	return nil
+}
+
+// notify calls the OnChange callbacks for a change, releasing the lock for the duration
+func (w *Watcher) notify(change published, onChange []func(prev, next interface{})) {
+	w.mu.Unlock()
+	defer w.mu.Lock()
+
+	for _, f := range onChange {
+		f(change.prev, change.next)
+	}
+}
+
+// publish stores a newly validated configuration and queues the change, it is called holding the lock
+func (w *Watcher) publish() error {
+	t := reflect.TypeOf(w.env.config)
+	if t == nil || t.Kind() != reflect.Ptr {
+		return ErrExpectedPointerValue
+	}
+
+	next := reflect.New(t.Elem()).Interface()
+	if err := validate(next, newReader(w.env.opts)); err != nil {
+		return err
+	}
+
+	if prev := w.current.Load(); prev != nil {
+		w.pending = append(w.pending, published{prev, next})
+	}
+	w.current.Store(next)
+
+	return nil
+}
+
+// Stop ends watching for signals and file changes
+func (w *Watcher) Stop() {
+	w.closeOnce.Do(func() { close(w.done) })
+}
+
+func (w *Watcher) run(sigs chan os.Signal, files []string, stamps []fileStamp, interval time.Duration) {
+	defer signal.Stop(sigs)
+
+	var tick <-chan time.Time
+	if len(files) > 0 {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-w.done:
+			return
+
+		case <-sigs:
+			w.reload()
+
+		case <-tick:
+			next := statFiles(files)
+			if !reflect.DeepEqual(stamps, next) {
+				stamps = next
+				w.reload()
+			}
+		}
+	}
+}
+
+func (w *Watcher) reload() {
+	if err := w.Reload(); err != nil {
+		w.mu.Lock()
+		onError := append([]func(error){}, w.onError...)
+		w.mu.Unlock()
+
+		for _, f := range onError {
+			f(err)
+		}
+	}
+}
+
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// statFiles records the modification state of files, missing files have a zero stamp
+func statFiles(files []string) []fileStamp {
+	stamps := make([]fileStamp, len(files))
+	for n, name := range files {
+		if fi, err := os.Stat(name); err == nil {
+			stamps[n] = fileStamp{fi.ModTime(), fi.Size()}
+		}
+	}
+	return stamps
+}
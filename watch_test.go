@@ -0,0 +1,210 @@
+package env
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatcherReload(t *testing.T) {
+	type config struct {
+		Beep NonEmptyString `env:"BEEP"`
+	}
+
+	var (
+		mu  sync.Mutex
+		env = map[string]string{"BEEP": "one"}
+	)
+	getter := func(k string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return env[k]
+	}
+
+	w, err := New(&config{}, &Options{Getenv: getter}).Watch()
+	assert.Nil(t, err)
+	defer w.Stop()
+
+	assert.Equal(t, &config{"one"}, w.Current())
+
+	var changes [][2]interface{}
+	w.OnChange(func(prev, next interface{}) { changes = append(changes, [2]interface{}{prev, next}) })
+
+	mu.Lock()
+	env["BEEP"] = ""
+	mu.Unlock()
+
+	assert.Error(t, w.Reload())
+	assert.Equal(t, &config{"one"}, w.Current())
+	assert.Empty(t, changes)
+
+	mu.Lock()
+	env["BEEP"] = "two"
+	mu.Unlock()
+
+	assert.Nil(t, w.Reload())
+	assert.Equal(t, &config{"two"}, w.Current())
+	assert.Equal(t, [][2]interface{}{{&config{"one"}, &config{"two"}}}, changes)
+}
+
+func TestWatcherFiles(t *testing.T) {
+	type config struct {
+		Beep NonEmptyString `env:"BEEP"`
+	}
+
+	dir, err := ioutil.TempDir("", "go-env")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	name := filepath.Join(dir, "beep")
+	assert.Nil(t, ioutil.WriteFile(name, []byte("one"), 0600))
+
+	getter := func(k string) string {
+		b, _ := ioutil.ReadFile(filepath.Join(dir, strings.ToLower(k)))
+		return string(b)
+	}
+
+	w, err := New(&config{}, &Options{Getenv: getter}).Watch(&WatchOptions{
+		Files:    []string{name},
+		Interval: 10 * time.Millisecond,
+	})
+	assert.Nil(t, err)
+	defer w.Stop()
+
+	changed := make(chan interface{}, 1)
+	w.OnChange(func(_, next interface{}) { changed <- next })
+
+	assert.Nil(t, ioutil.WriteFile(name, []byte("three"), 0600))
+
+	select {
+	case next := <-changed:
+		assert.Equal(t, &config{"three"}, next)
+		assert.Equal(t, &config{"three"}, w.Current())
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected a reload after the file changed")
+	}
+}
+
+func TestWatcherCallbacksUseWatcher(t *testing.T) {
+	type config struct {
+		Beep NonEmptyString `env:"BEEP"`
+	}
+
+	var (
+		mu  sync.Mutex
+		env = map[string]string{"BEEP": "one"}
+	)
+	getter := func(k string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		return env[k]
+	}
+
+	w, err := New(&config{}, &Options{Getenv: getter}).Watch()
+	assert.Nil(t, err)
+	defer w.Stop()
+
+	reloaded := false
+	w.OnChange(func(_, _ interface{}) {
+		w.OnError(func(error) {})
+		if !reloaded {
+			reloaded = true
+			assert.Nil(t, w.Reload())
+		}
+	})
+
+	errs := make(chan error, 1)
+	w.OnError(func(err error) {
+		w.OnChange(func(_, _ interface{}) {})
+		errs <- w.Reload()
+	})
+
+	mu.Lock()
+	env["BEEP"] = "two"
+	mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		assert.Nil(t, w.Reload())
+
+		mu.Lock()
+		env["BEEP"] = ""
+		mu.Unlock()
+		w.reload()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		assert.True(t, reloaded)
+		assert.Error(t, <-errs)
+		assert.Equal(t, &config{"two"}, w.Current())
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected callbacks to use the watcher without deadlocking")
+	}
+}
+
+func TestWatcherConcurrentReloads(t *testing.T) {
+	type config struct {
+		Beep Int `env:"BEEP"`
+	}
+
+	var (
+		mu sync.Mutex
+		n  int
+	)
+	getter := func(string) string {
+		mu.Lock()
+		defer mu.Unlock()
+		n++
+		return strconv.Itoa(n)
+	}
+
+	w, err := New(&config{}, &Options{Getenv: getter}).Watch()
+	assert.Nil(t, err)
+	defer w.Stop()
+
+	var (
+		changesMu sync.Mutex
+		changes   [][2]interface{}
+	)
+	w.OnChange(func(prev, next interface{}) {
+		// Slow listeners must not let a later change overtake an earlier one
+		if next.(*config).Beep%2 == 1 {
+			time.Sleep(2 * time.Millisecond)
+		}
+		changesMu.Lock()
+		changes = append(changes, [2]interface{}{prev, next})
+		changesMu.Unlock()
+	})
+
+	initial := w.Current()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			assert.Nil(t, w.Reload())
+		}()
+	}
+	wg.Wait()
+
+	changesMu.Lock()
+	defer changesMu.Unlock()
+
+	assert.Len(t, changes, 20)
+	current := initial
+	for _, c := range changes {
+		assert.True(t, c[0] == current, "expected changes in publishing order")
+		current = c[1]
+	}
+	assert.True(t, current == w.Current())
+}